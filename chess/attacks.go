@@ -0,0 +1,143 @@
+package chess
+
+// Precomputed attack sets for non-sliding pieces, indexed by square.
+var (
+	kingTable   [64]Bitboard
+	knightTable [64]Bitboard
+	pawnTable   [2][64]Bitboard // Indexed by [Color.index].
+)
+
+// Precomputed lines between pairs of squares. For squares a and b that share a
+// rank, file or diagonal, between[a][b] contains the squares strictly between
+// them and line[a][b] contains the entire board line through both of them.
+// Both are empty for unaligned squares.
+var (
+	between [64][64]Bitboard
+	line    [64][64]Bitboard
+)
+
+// A delta is a file and rank offset.
+type delta struct {
+	df, dr int
+}
+
+var (
+	kingDeltas   = []delta{{-1, -1}, {0, -1}, {1, -1}, {-1, 0}, {1, 0}, {-1, 1}, {0, 1}, {1, 1}}
+	knightDeltas = []delta{{-1, -2}, {1, -2}, {-2, -1}, {2, -1}, {-2, 1}, {2, 1}, {-1, 2}, {1, 2}}
+	rookDeltas   = []delta{{0, -1}, {-1, 0}, {1, 0}, {0, 1}}
+	bishopDeltas = []delta{{-1, -1}, {1, -1}, {-1, 1}, {1, 1}}
+)
+
+func init() {
+	for s := A1; s <= H8; s++ {
+		for _, d := range kingDeltas {
+			if t, ok := s.offset(d); ok {
+				kingTable[s].Set(t)
+			}
+		}
+
+		for _, d := range knightDeltas {
+			if t, ok := s.offset(d); ok {
+				knightTable[s].Set(t)
+			}
+		}
+
+		for _, d := range []delta{{-1, 1}, {1, 1}} {
+			if t, ok := s.offset(d); ok {
+				pawnTable[White.index()][s].Set(t)
+			}
+		}
+
+		for _, d := range []delta{{-1, -1}, {1, -1}} {
+			if t, ok := s.offset(d); ok {
+				pawnTable[Black.index()][s].Set(t)
+			}
+		}
+
+		for _, d := range kingDeltas {
+			full := s.Bitboard() | ray(s, d) | ray(s, delta{-d.df, -d.dr})
+
+			var prev Bitboard
+			for t, ok := s.offset(d); ok; t, ok = t.offset(d) {
+				between[s][t] = prev
+				line[s][t] = full
+				prev.Set(t)
+			}
+		}
+	}
+}
+
+// offset returns the square offset from s by d, if it is on the board.
+func (s Square) offset(d delta) (Square, bool) {
+	f, r := int(s.File())+d.df, int(s.Rank())+d.dr
+	if f < 0 || f > 7 || r < 0 || r > 7 {
+		return 0, false
+	}
+	return SquareAt(File(f), Rank(r)), true
+}
+
+// ray returns all squares reachable from s in direction d on an empty board.
+func ray(s Square, d delta) Bitboard {
+	return slidingAttacks(s, 0, []delta{d})
+}
+
+// slidingAttacks returns the squares attacked from s by a piece that slides
+// along the given directions, stopping at the first occupied square in each.
+func slidingAttacks(s Square, occupied Bitboard, deltas []delta) Bitboard {
+	var bb Bitboard
+	for _, d := range deltas {
+		for t, ok := s.offset(d); ok; t, ok = t.offset(d) {
+			bb.Set(t)
+			if occupied.Get(t) {
+				break
+			}
+		}
+	}
+	return bb
+}
+
+// KingAttacks returns the squares attacked by a king on s.
+func KingAttacks(s Square) Bitboard {
+	return kingTable[s]
+}
+
+// KnightAttacks returns the squares attacked by a knight on s.
+func KnightAttacks(s Square) Bitboard {
+	return knightTable[s]
+}
+
+// PawnAttacks returns the squares attacked by a pawn of color c on s.
+func PawnAttacks(c Color, s Square) Bitboard {
+	return pawnTable[c.index()][s]
+}
+
+// RookAttacks returns the squares attacked by a rook on s, given the occupied
+// squares. The attacks include the first occupied square in each direction,
+// regardless of its color.
+func RookAttacks(s Square, occupied Bitboard) Bitboard {
+	return rookMagics[s].attacks[rookMagics[s].index(occupied)]
+}
+
+// BishopAttacks returns the squares attacked by a bishop on s, given the
+// occupied squares. The attacks include the first occupied square in each
+// direction, regardless of its color.
+func BishopAttacks(s Square, occupied Bitboard) Bitboard {
+	return bishopMagics[s].attacks[bishopMagics[s].index(occupied)]
+}
+
+// QueenAttacks returns the squares attacked by a queen on s, given the occupied
+// squares.
+func QueenAttacks(s Square, occupied Bitboard) Bitboard {
+	return RookAttacks(s, occupied) | BishopAttacks(s, occupied)
+}
+
+// attackersTo returns the pieces of color c that attack square s, given the
+// occupied squares.
+func (b *Board) attackersTo(s Square, c Color, occupied Bitboard) Bitboard {
+	attackers := KnightAttacks(s)&b.knights |
+		KingAttacks(s)&b.kings |
+		PawnAttacks(c.Other(), s)&b.pawns |
+		RookAttacks(s, occupied)&(b.rooks|b.queens) |
+		BishopAttacks(s, occupied)&(b.bishops|b.queens)
+	return attackers & b.ByColor(c)
+}
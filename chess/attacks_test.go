@@ -0,0 +1,73 @@
+package chess
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestRookAttacks(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		s := Square(rng.Intn(64))
+		occupied := Bitboard(rng.Uint64() & rng.Uint64())
+
+		want := slidingAttacks(s, occupied, rookDeltas)
+		if got := RookAttacks(s, occupied); got != want {
+			t.Fatalf("%v, %#x: got %#x, want %#x", s, occupied, got, want)
+		}
+	}
+}
+
+func TestBishopAttacks(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		s := Square(rng.Intn(64))
+		occupied := Bitboard(rng.Uint64() & rng.Uint64())
+
+		want := slidingAttacks(s, occupied, bishopDeltas)
+		if got := BishopAttacks(s, occupied); got != want {
+			t.Fatalf("%v, %#x: got %#x, want %#x", s, occupied, got, want)
+		}
+	}
+}
+
+func TestKnightAttacks(t *testing.T) {
+	cases := []struct {
+		s    Square
+		want int
+	}{
+		{A1, 2},
+		{B1, 3},
+		{D4, 8},
+		{H8, 2},
+	}
+	for _, tc := range cases {
+		bb := KnightAttacks(tc.s)
+		if got := bb.Count(); got != tc.want {
+			t.Errorf("%v: got %d attacks, want %d", tc.s, got, tc.want)
+		}
+	}
+}
+
+func ExampleRookAttacks() {
+	var occupied Bitboard
+	occupied.Set(D6)
+	occupied.Set(F4)
+
+	bb := RookAttacks(D4, occupied)
+	fmt.Println(bb.Count(), bb.Get(D6), bb.Get(D7), bb.Get(F4), bb.Get(G4))
+	// Output:
+	// 10 true false true false
+}
+
+func ExamplePawnAttacks() {
+	bb := PawnAttacks(White, E4)
+	fmt.Println(bb.Get(D5), bb.Get(F5), bb.Get(E5))
+
+	bb = PawnAttacks(Black, A5)
+	fmt.Println(bb.Count(), bb.Get(B4))
+	// Output:
+	// true true false
+	// 1 true
+}
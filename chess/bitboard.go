@@ -46,3 +46,16 @@ func (b *Bitboard) IsFull() bool {
 func (b *Bitboard) Square() Square {
 	return Square(bits.TrailingZeros64(uint64(*b)))
 }
+
+// Count returns the number of set bits.
+func (b *Bitboard) Count() int {
+	return bits.OnesCount64(uint64(*b))
+}
+
+// Pop clears the least significant set bit and returns its square. Calling Pop
+// on an empty Bitboard returns an invalid square.
+func (b *Bitboard) Pop() Square {
+	s := b.Square()
+	*b &= *b - 1
+	return s
+}
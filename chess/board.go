@@ -52,10 +52,15 @@ func (b *Board) ByColor(c Color) Bitboard {
 
 // KingOf returns the square of the king of the given color.
 func (b *Board) KingOf(c Color) Square {
-	bb := b.ByColor(c) | b.kings
+	bb := b.ByColor(c) & b.kings
 	return bb.Square()
 }
 
+// Occupied returns a bitboard of all occupied squares.
+func (b *Board) Occupied() Bitboard {
+	return b.white | b.black
+}
+
 // Put puts a piece on a square. Any piece already on the square is removed.
 func (b *Board) Put(p Piece, s Square) {
 	b.Remove(s)
@@ -204,18 +209,15 @@ func (b *Board) IsValid() error {
 
 	// Requirement: Both kings are not simultaneously in check.
 
-	// Requirement: No pawns are on the first or eighth rank.
-
-	for s := A1; s <= A8; s++ {
-		if b.pawns.Get(s) {
-			return fmt.Errorf("pawn on square %v", s)
-		}
+	occupied := b.Occupied()
+	if b.attackersTo(whiteKingSquare, Black, occupied) != 0 && b.attackersTo(blackKingSquare, White, occupied) != 0 {
+		return fmt.Errorf("both kings are in check")
 	}
 
-	for s := H1; s <= H8; s++ {
-		if b.pawns.Get(s) {
-			return fmt.Errorf("pawn on square %v", s)
-		}
+	// Requirement: No pawns are on the first or eighth rank.
+
+	if bb := b.pawns & (rankBitboard(Rank1) | rankBitboard(Rank8)); bb != 0 {
+		return fmt.Errorf("pawn on square %v", bb.Square())
 	}
 
 	return nil
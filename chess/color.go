@@ -16,3 +16,16 @@ func (c Color) String() string {
 	}
 	return "White"
 }
+
+// Other returns the opposite color.
+func (c Color) Other() Color {
+	return !c
+}
+
+// index returns 0 for White and 1 for Black, for use in lookup tables.
+func (c Color) index() int {
+	if c {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,55 @@
+package chess
+
+// lightSquares is a bitboard of the light squares.
+const lightSquares Bitboard = 0x55AA_55AA_55AA_55AA
+
+// IsFiftyMoveDraw returns true if either player may claim a draw by the
+// fifty-move rule: fifty moves by each player without a capture or pawn move.
+// A checkmate on the fiftieth move takes precedence.
+func (p *Position) IsFiftyMoveDraw() bool {
+	return p.HalfMoveClock >= 100 && !p.IsCheckmate()
+}
+
+// IsSeventyFiveMoveDraw returns true if the game is drawn by the seventy-five
+// move rule: seventy-five moves by each player without a capture or pawn move.
+// Unlike [Position.IsFiftyMoveDraw], the draw is automatic. A checkmate on the
+// seventy-fifth move takes precedence.
+func (p *Position) IsSeventyFiveMoveDraw() bool {
+	return p.HalfMoveClock >= 150 && !p.IsCheckmate()
+}
+
+// HasInsufficientMaterial returns true if neither player can checkmate by any
+// sequence of legal moves because too little material remains. That is the
+// case for a lone king against a king and at most one minor piece, and for
+// kings and bishops only with all bishops on squares of the same color.
+func (p *Position) HasInsufficientMaterial() bool {
+	b := &p.Board
+
+	if b.pawns|b.rooks|b.queens != 0 {
+		return false
+	}
+
+	minors := b.knights | b.bishops
+	if minors.Count() <= 1 {
+		return true
+	}
+
+	return b.knights == 0 && (b.bishops&lightSquares == 0 || b.bishops&^lightSquares == 0)
+}
+
+// CountRepetitions returns the number of times a position with the given hash
+// occurred earlier. The history holds the hashes of the positions before it,
+// oldest first, and halfMoveClock is its half move clock.
+func CountRepetitions(history []uint64, hash uint64, halfMoveClock uint8) int {
+	n := 0
+
+	// Only positions since the last capture or pawn move can repeat, and only
+	// those with the same side to move.
+	for i := len(history) - 2; i >= 0 && i >= len(history)-int(halfMoveClock); i -= 2 {
+		if history[i] == hash {
+			n++
+		}
+	}
+
+	return n
+}
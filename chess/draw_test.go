@@ -0,0 +1,82 @@
+package chess_test
+
+import (
+	"testing"
+
+	"github.com/clfs/aloe/chess"
+	"github.com/clfs/aloe/fen"
+)
+
+func TestPosition_HasInsufficientMaterial(t *testing.T) {
+	cases := []struct {
+		fen  string
+		want bool
+	}{
+		{fen.StartingFEN, false},
+		{"4k3/8/8/8/8/8/8/4K3 w - - 0 1", true},
+		{"4k3/8/8/8/8/8/8/4KN2 w - - 0 1", true},
+		{"4kb2/8/8/8/8/8/8/4K3 w - - 0 1", true},
+		{"4kb2/8/8/8/8/8/8/2B1K3 w - - 0 1", true},
+		{"4k1b1/8/8/8/8/8/8/2B1K3 w - - 0 1", false},
+		{"4k3/8/8/8/8/8/8/3NKN2 w - - 0 1", false},
+		{"4kn2/8/8/8/8/8/8/4KN2 w - - 0 1", false},
+		{"4k3/8/8/8/8/8/8/4K2R w - - 0 1", false},
+		{"4k3/8/8/8/8/8/4P3/4K3 w - - 0 1", false},
+	}
+	for _, tc := range cases {
+		p, err := fen.Decode(tc.fen)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.fen, err)
+		}
+		if got := p.HasInsufficientMaterial(); got != tc.want {
+			t.Errorf("%q: got %t, want %t", tc.fen, got, tc.want)
+		}
+	}
+}
+
+func TestPosition_IsFiftyMoveDraw(t *testing.T) {
+	cases := []struct {
+		fen                string
+		fifty, seventyFive bool
+	}{
+		{"4k3/8/8/8/8/8/8/4K2R w - - 99 80", false, false},
+		{"4k3/8/8/8/8/8/8/4K2R w - - 100 80", true, false},
+		{"4k3/8/8/8/8/8/8/4K2R w - - 149 80", true, false},
+		{"4k3/8/8/8/8/8/8/4K2R w - - 150 80", true, true},
+		// Checkmate takes precedence.
+		{"7k/6Q1/6K1/8/8/8/8/8 b - - 150 80", false, false},
+	}
+	for _, tc := range cases {
+		p, err := fen.Decode(tc.fen)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.fen, err)
+		}
+		if got := p.IsFiftyMoveDraw(); got != tc.fifty {
+			t.Errorf("%q: IsFiftyMoveDraw: got %t, want %t", tc.fen, got, tc.fifty)
+		}
+		if got := p.IsSeventyFiveMoveDraw(); got != tc.seventyFive {
+			t.Errorf("%q: IsSeventyFiveMoveDraw: got %t, want %t", tc.fen, got, tc.seventyFive)
+		}
+	}
+}
+
+func TestCountRepetitions(t *testing.T) {
+	history := []uint64{1, 2, 3, 4, 1, 2, 3, 4}
+
+	cases := []struct {
+		hash          uint64
+		halfMoveClock uint8
+		want          int
+	}{
+		{3, 8, 2},
+		{3, 4, 1},
+		{3, 1, 0},
+		{4, 8, 0}, // Same hash, other side to move.
+		{5, 8, 0},
+	}
+	for _, tc := range cases {
+		if got := chess.CountRepetitions(history, tc.hash, tc.halfMoveClock); got != tc.want {
+			t.Errorf("hash %d, clock %d: got %d, want %d", tc.hash, tc.halfMoveClock, got, tc.want)
+		}
+	}
+}
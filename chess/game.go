@@ -0,0 +1,73 @@
+package chess
+
+// Game is a chess game. It tracks the positions that led to the current one,
+// which is needed to detect repetitions.
+//
+// The zero value for Game is not usable; create one with [NewGame].
+type Game struct {
+	pos    Position
+	undos  []*Undo
+	hashes []uint64 // Hashes of the earlier positions, oldest first.
+}
+
+// NewGame returns a new game starting from the given position.
+func NewGame(p Position) *Game {
+	return &Game{pos: p}
+}
+
+// Position returns the current position.
+func (g *Game) Position() Position {
+	return g.pos
+}
+
+// Moves returns the moves played so far, oldest first.
+func (g *Game) Moves() []Move {
+	moves := make([]Move, len(g.undos))
+	for i, u := range g.undos {
+		moves[i] = u.Move
+	}
+	return moves
+}
+
+// Move plays a move. The move must be legal by the definition of
+// [Position.IsLegalMove]. If not, behavior is undefined.
+func (g *Game) Move(m Move) {
+	g.hashes = append(g.hashes, g.pos.Hash())
+	g.undos = append(g.undos, g.pos.Move(m))
+}
+
+// Undo takes back the last move played and returns it. If no moves have been
+// played, ok is false.
+func (g *Game) Undo() (m Move, ok bool) {
+	n := len(g.undos)
+	if n == 0 {
+		return Move{}, false
+	}
+
+	u := g.undos[n-1]
+	g.pos.Undo(u)
+
+	g.undos = g.undos[:n-1]
+	g.hashes = g.hashes[:n-1]
+
+	return u.Move, true
+}
+
+// Repetitions returns the number of times the current position has occurred in
+// the game, including now.
+func (g *Game) Repetitions() int {
+	return 1 + CountRepetitions(g.hashes, g.pos.Hash(), g.pos.HalfMoveClock)
+}
+
+// IsThreefoldRepetition returns true if either player may claim a draw because
+// the current position has occurred at least three times.
+func (g *Game) IsThreefoldRepetition() bool {
+	return g.Repetitions() >= 3
+}
+
+// IsFivefoldRepetition returns true if the game is drawn because the current
+// position has occurred at least five times. Unlike
+// [Game.IsThreefoldRepetition], the draw is automatic.
+func (g *Game) IsFivefoldRepetition() bool {
+	return g.Repetitions() >= 5
+}
@@ -0,0 +1,75 @@
+package chess_test
+
+import (
+	"testing"
+
+	"github.com/clfs/aloe/chess"
+)
+
+func TestGame_Repetitions(t *testing.T) {
+	g := chess.NewGame(chess.NewPosition())
+
+	shuffle := []string{"g1f3", "g8f6", "f3g1", "f6g8"}
+
+	for i := 1; i <= 5; i++ {
+		if got := g.Repetitions(); got != i {
+			t.Fatalf("got %d repetitions, want %d", got, i)
+		}
+		if got, want := g.IsThreefoldRepetition(), i >= 3; got != want {
+			t.Errorf("%d: IsThreefoldRepetition: got %t, want %t", i, got, want)
+		}
+		if got, want := g.IsFivefoldRepetition(), i >= 5; got != want {
+			t.Errorf("%d: IsFivefoldRepetition: got %t, want %t", i, got, want)
+		}
+
+		for _, s := range shuffle {
+			m, _ := chess.NewMove(s)
+			g.Move(m)
+		}
+	}
+
+	// Undoing restores the earlier count.
+	for i := 0; i < len(shuffle); i++ {
+		if _, ok := g.Undo(); !ok {
+			t.Fatal("nothing to undo")
+		}
+	}
+	if got, want := g.Repetitions(), 5; got != want {
+		t.Errorf("after undo: got %d repetitions, want %d", got, want)
+	}
+
+	// A pawn move is irreversible.
+	m, _ := chess.NewMove("e2e4")
+	g.Move(m)
+	if got, want := g.Repetitions(), 1; got != want {
+		t.Errorf("after pawn move: got %d repetitions, want %d", got, want)
+	}
+}
+
+func TestGame_Undo(t *testing.T) {
+	start := chess.NewPosition()
+	g := chess.NewGame(start)
+
+	if _, ok := g.Undo(); ok {
+		t.Error("undo succeeded with no moves")
+	}
+
+	e4, _ := chess.NewMove("e2e4")
+	e5, _ := chess.NewMove("e7e5")
+	g.Move(e4)
+	g.Move(e5)
+
+	if got := g.Moves(); len(got) != 2 || got[0] != e4 || got[1] != e5 {
+		t.Errorf("got moves %v", got)
+	}
+
+	for _, want := range []chess.Move{e5, e4} {
+		if got, ok := g.Undo(); !ok || got != want {
+			t.Errorf("undo: got %v, %t, want %v", got, ok, want)
+		}
+	}
+
+	if g.Position() != start {
+		t.Error("position not restored")
+	}
+}
@@ -50,3 +50,29 @@ func NewMove(s string) (Move, error) {
 
 	return Move{from, to, promo}, nil
 }
+
+// LAN returns the move in UCI-compatible long algebraic notation (LAN), as
+// accepted by [NewMove]. The null move is "0000".
+func (m Move) LAN() string {
+	if m == (Move{}) {
+		return "0000"
+	}
+
+	b := []byte{
+		'a' + byte(m.From.File()), '1' + byte(m.From.Rank()),
+		'a' + byte(m.To.File()), '1' + byte(m.To.Rank()),
+	}
+
+	switch m.PromotionInfo {
+	case KnightPromotion:
+		b = append(b, 'n')
+	case BishopPromotion:
+		b = append(b, 'b')
+	case RookPromotion:
+		b = append(b, 'r')
+	case QueenPromotion:
+		b = append(b, 'q')
+	}
+
+	return string(b)
+}
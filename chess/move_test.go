@@ -32,6 +32,31 @@ func TestNewMove(t *testing.T) {
 	}
 }
 
+func TestMove_LAN(t *testing.T) {
+	cases := []struct {
+		in   Move
+		want string
+	}{
+		{Move{From: E2, To: E4}, "e2e4"},
+		{Move{From: E7, To: E8, PromotionInfo: QueenPromotion}, "e7e8q"},
+		{Move{From: B2, To: A1, PromotionInfo: KnightPromotion}, "b2a1n"},
+		{Move{}, "0000"},
+	}
+	for _, c := range cases {
+		if got := c.in.LAN(); got != c.want {
+			t.Errorf("%+v: want %q, got %q", c.in, c.want, got)
+		}
+
+		if c.in == (Move{}) {
+			continue
+		}
+
+		if m, err := NewMove(c.want); err != nil || m != c.in {
+			t.Errorf("%q: round trip failed: %+v, %v", c.want, m, err)
+		}
+	}
+}
+
 func ExampleNewMove() {
 	m, _ := NewMove("e2e4")
 	fmt.Printf("%+v\n", m)
@@ -0,0 +1,256 @@
+package chess
+
+// castle describes one of the four castling moves.
+type castle struct {
+	right        CastleRights
+	color        Color
+	king, kingTo Square
+	rook, rookTo Square
+	empty        Bitboard // Squares that must be empty.
+	safe         Bitboard // Squares the king passes through or lands on.
+}
+
+var castles = [...]castle{
+	{
+		right: WhiteOO, color: White,
+		king: E1, kingTo: G1, rook: H1, rookTo: F1,
+		empty: F1.Bitboard() | G1.Bitboard(),
+		safe:  F1.Bitboard() | G1.Bitboard(),
+	},
+	{
+		right: WhiteOOO, color: White,
+		king: E1, kingTo: C1, rook: A1, rookTo: D1,
+		empty: B1.Bitboard() | C1.Bitboard() | D1.Bitboard(),
+		safe:  C1.Bitboard() | D1.Bitboard(),
+	},
+	{
+		right: BlackOO, color: Black,
+		king: E8, kingTo: G8, rook: H8, rookTo: F8,
+		empty: F8.Bitboard() | G8.Bitboard(),
+		safe:  F8.Bitboard() | G8.Bitboard(),
+	},
+	{
+		right: BlackOOO, color: Black,
+		king: E8, kingTo: C8, rook: A8, rookTo: D8,
+		empty: B8.Bitboard() | C8.Bitboard() | D8.Bitboard(),
+		safe:  C8.Bitboard() | D8.Bitboard(),
+	},
+}
+
+// castleRightsLost maps each square to the castle rights forfeited by moving a
+// piece from or to it.
+var castleRightsLost [64]CastleRights
+
+func init() {
+	for _, c := range castles {
+		castleRightsLost[c.king] |= c.right
+		castleRightsLost[c.rook] |= c.right
+	}
+}
+
+// castleFor returns the castling move made by a king moving as in m, if any.
+func castleFor(m Move) (castle, bool) {
+	for _, c := range castles {
+		if m.From == c.king && m.To == c.kingTo {
+			return c, true
+		}
+	}
+	return castle{}, false
+}
+
+// promotions lists the promotion choices in the order they are generated.
+var promotions = [...]PromotionInfo{QueenPromotion, RookPromotion, BishopPromotion, KnightPromotion}
+
+// LegalMoves returns a list of legal moves.
+func (p *Position) LegalMoves() []Move {
+	moves := make([]Move, 0, 64)
+
+	us, them := p.SideToMove, p.SideToMove.Other()
+	ours, theirs := p.Board.ByColor(us), p.Board.ByColor(them)
+	occupied := ours | theirs
+	king := p.Board.KingOf(us)
+
+	// King moves. The king is removed from the occupancy when testing its
+	// destination, so it can't step backwards along a checking slider's line.
+	targets := KingAttacks(king) &^ ours
+	for targets != 0 {
+		to := targets.Pop()
+		if p.Board.attackersTo(to, them, occupied&^king.Bitboard()) == 0 {
+			moves = append(moves, Move{From: king, To: to})
+		}
+	}
+
+	checkers := p.Board.attackersTo(king, them, occupied)
+
+	// In double check, only the king can move.
+	if checkers.Count() > 1 {
+		return moves
+	}
+
+	// Non-king moves must land on a square in the mask. In check, that means
+	// capturing the checker or blocking it.
+	mask := ^ours
+	if checkers != 0 {
+		mask &= checkers | between[king][checkers.Square()]
+	}
+
+	pinned := p.pinned(king, us)
+
+	// appendMoves appends moves from a non-pawn piece to its targets.
+	appendMoves := func(from Square, targets Bitboard) {
+		targets &= mask
+		if pinned.Get(from) {
+			targets &= line[king][from]
+		}
+		for targets != 0 {
+			moves = append(moves, Move{From: from, To: targets.Pop()})
+		}
+	}
+
+	for bb := ours & p.Board.knights; bb != 0; {
+		from := bb.Pop()
+		appendMoves(from, KnightAttacks(from))
+	}
+
+	for bb := ours & (p.Board.bishops | p.Board.queens); bb != 0; {
+		from := bb.Pop()
+		appendMoves(from, BishopAttacks(from, occupied))
+	}
+
+	for bb := ours & (p.Board.rooks | p.Board.queens); bb != 0; {
+		from := bb.Pop()
+		appendMoves(from, RookAttacks(from, occupied))
+	}
+
+	// Pawn moves.
+	var (
+		startRank, promotionRank = Rank2, Rank8
+		forward                  = Square(8)
+	)
+	if us == Black {
+		startRank, promotionRank = Rank7, Rank1
+		forward = -forward
+	}
+
+	for bb := ours & p.Board.pawns; bb != 0; {
+		from := bb.Pop()
+
+		attacks := PawnAttacks(us, from)
+
+		targets := attacks & theirs
+		if one := from + forward; !occupied.Get(one) {
+			targets.Set(one)
+			if two := one + forward; from.Rank() == startRank && !occupied.Get(two) {
+				targets.Set(two)
+			}
+		}
+
+		targets &= mask
+		if pinned.Get(from) {
+			targets &= line[king][from]
+		}
+
+		for targets != 0 {
+			to := targets.Pop()
+			if to.Rank() != promotionRank {
+				moves = append(moves, Move{From: from, To: to})
+				continue
+			}
+			for _, promo := range promotions {
+				moves = append(moves, Move{From: from, To: to, PromotionInfo: promo})
+			}
+		}
+
+		if p.EnPassantFlag && attacks.Get(p.EnPassantSquare) {
+			if p.isLegalEnPassant(from, king) {
+				moves = append(moves, Move{From: from, To: p.EnPassantSquare})
+			}
+		}
+	}
+
+	// Castling.
+	if checkers == 0 {
+		for _, c := range castles {
+			if p.canCastle(c, occupied) {
+				moves = append(moves, Move{From: c.king, To: c.kingTo})
+			}
+		}
+	}
+
+	return moves
+}
+
+// IsLegalMove returns true if the move is legal in the position. It does not
+// account for insufficient material or three-fold repetition.
+func (p *Position) IsLegalMove(m Move) bool {
+	for _, legal := range p.LegalMoves() {
+		if m == legal {
+			return true
+		}
+	}
+	return false
+}
+
+// pinned returns the pieces of color c that are pinned to the king on the
+// given square.
+func (p *Position) pinned(king Square, c Color) Bitboard {
+	ours, theirs := p.Board.ByColor(c), p.Board.ByColor(c.Other())
+
+	// Enemy sliders that would attack the king if not for blockers.
+	snipers := RookAttacks(king, theirs)&(p.Board.rooks|p.Board.queens) |
+		BishopAttacks(king, theirs)&(p.Board.bishops|p.Board.queens)
+	snipers &= theirs
+
+	var pinned Bitboard
+	for snipers != 0 {
+		blockers := between[king][snipers.Pop()] & (ours | theirs)
+		if blockers.Count() == 1 && blockers&ours != 0 {
+			pinned |= blockers
+		}
+	}
+	return pinned
+}
+
+// isLegalEnPassant reports whether capturing en passant with the pawn on from
+// leaves the king on the given square safe. Both the capturing and the
+// captured pawn leave their squares, so the capture can expose the king along
+// a rank as well as a diagonal.
+func (p *Position) isLegalEnPassant(from, king Square) bool {
+	them := p.SideToMove.Other()
+
+	victim := SquareAt(p.EnPassantSquare.File(), from.Rank())
+	occupied := p.Board.Occupied()
+	occupied &^= from.Bitboard() | victim.Bitboard()
+	occupied |= p.EnPassantSquare.Bitboard()
+
+	attackers := p.Board.attackersTo(king, them, occupied) &^ victim.Bitboard()
+	return attackers == 0
+}
+
+// canCastle reports whether the side to move may make the castling move c. The
+// king must not be in check; that is checked by the caller.
+func (p *Position) canCastle(c castle, occupied Bitboard) bool {
+	if c.color != p.SideToMove || !p.CastleRights.Contains(c.right) {
+		return false
+	}
+
+	if occupied&c.empty != 0 {
+		return false
+	}
+
+	if piece, ok := p.Board.At(c.king); !ok || piece != (Piece{c.color, King}) {
+		return false
+	}
+
+	if piece, ok := p.Board.At(c.rook); !ok || piece != (Piece{c.color, Rook}) {
+		return false
+	}
+
+	for safe := c.safe; safe != 0; {
+		if p.Board.attackersTo(safe.Pop(), c.color.Other(), occupied) != 0 {
+			return false
+		}
+	}
+
+	return true
+}
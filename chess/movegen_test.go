@@ -0,0 +1,55 @@
+package chess_test
+
+import (
+	"testing"
+
+	"github.com/clfs/aloe/chess"
+	"github.com/clfs/aloe/fen"
+)
+
+func TestPosition_LegalMoves(t *testing.T) {
+	cases := []struct {
+		fen  string
+		want int
+	}{
+		{fen.StartingFEN, 20},
+		{"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", 48},
+		{"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", 14},
+		{"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1", 6},
+		{"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8", 44},
+		{"r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10", 46},
+		// En passant would expose the king along the fifth rank.
+		{"8/8/8/KPp4r/8/8/8/7k w - c6 0 1", 4},
+		// Double check: only the king may move.
+		{"4k3/8/8/8/8/5n2/8/R3K2r w Q - 0 1", 2},
+		// Castling queenside through an attacked square is illegal.
+		{"4k3/8/8/8/8/8/3r4/R3K2R w KQ - 0 1", 22},
+	}
+	for _, tc := range cases {
+		p, err := fen.Decode(tc.fen)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.fen, err)
+		}
+		if got := len(p.LegalMoves()); got != tc.want {
+			t.Errorf("%q: got %d moves, want %d", tc.fen, got, tc.want)
+		}
+	}
+}
+
+func TestPosition_IsLegalMove(t *testing.T) {
+	p := chess.NewPosition()
+
+	for _, s := range []string{"e2e4", "g1f3", "b2b3"} {
+		m, _ := chess.NewMove(s)
+		if !p.IsLegalMove(m) {
+			t.Errorf("%s: want legal", s)
+		}
+	}
+
+	for _, s := range []string{"e2e5", "e1e2", "f1c4", "a7a6"} {
+		m, _ := chess.NewMove(s)
+		if p.IsLegalMove(m) {
+			t.Errorf("%s: want illegal", s)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package chess
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+)
 
 // Position is a chess position.
 type Position struct {
@@ -13,28 +16,19 @@ type Position struct {
 
 	FullMoveNumber uint16 // Number of full moves. Starts at 1 and increments after Black moves.
 	HalfMoveClock  uint8  // Number of plies since last capture or pawn move.
+
+	hash uint64 // Zobrist hash, maintained by Move and Undo.
 }
 
 // NewPosition returns a new starting position.
 func NewPosition() Position {
-	return Position{
+	p := Position{
 		Board:          NewBoard(),
 		CastleRights:   NewCastleRights(),
 		FullMoveNumber: 1,
 	}
-}
-
-// LegalMoves returns a list of legal moves.
-func (p *Position) LegalMoves() []Move {
-	var moves []Move
-
-	return moves
-}
-
-// IsLegalMove returns true if the move is legal in the position. It does not
-// account for insufficient material or three-fold repetition.
-func (p *Position) IsLegalMove(m Move) bool {
-	return false
+	p.ResetHash()
+	return p
 }
 
 // Move updates the position by making a move. It returns information that can
@@ -42,16 +36,118 @@ func (p *Position) IsLegalMove(m Move) bool {
 //
 // The move must be legal by the definition of [Position.IsLegalMove]. If not,
 // behavior is undefined.
-func (p *Position) Move(m Move) *Undo { return nil }
+func (p *Position) Move(m Move) *Undo {
+	u := &Undo{
+		Move:            m,
+		EnPassantFlag:   p.EnPassantFlag,
+		EnPassantSquare: p.EnPassantSquare,
+		CastleRights:    p.CastleRights,
+		HalfMoveClock:   p.HalfMoveClock,
+		Hash:            p.hash,
+	}
+
+	piece, _ := p.Board.At(m.From)
+
+	// Hash out the state that is about to change. It's hashed back in below.
+	p.hash ^= p.enPassantKey() ^ castleKeys[p.CastleRights]
+
+	// Remove the captured piece, if any. An en passant victim sits beside the
+	// capturing pawn rather than on the destination square.
+	captureSquare := m.To
+	if piece.Role == Pawn && p.EnPassantFlag && m.To == p.EnPassantSquare {
+		captureSquare = SquareAt(m.To.File(), m.From.Rank())
+		u.WasEnPassant = true
+	}
+
+	if captured, ok := p.Board.At(captureSquare); ok {
+		u.WasCapture = true
+		u.CapturedRole = captured.Role
+		p.Board.Remove(captureSquare)
+		p.hash ^= pieceKey(captured, captureSquare)
+	}
+
+	// Move the piece, promoting it if necessary.
+	moved := piece
+	if r, ok := m.PromotionInfo.Role(); ok {
+		moved.Role = r
+	}
+	p.Board.Remove(m.From)
+	p.Board.PutDangerous(moved, m.To)
+	p.hash ^= pieceKey(piece, m.From) ^ pieceKey(moved, m.To)
+
+	// Move the rook when castling.
+	if piece.Role == King {
+		if c, ok := castleFor(m); ok {
+			rook := Piece{piece.Color, Rook}
+			p.Board.Remove(c.rook)
+			p.Board.PutDangerous(rook, c.rookTo)
+			p.hash ^= pieceKey(rook, c.rook) ^ pieceKey(rook, c.rookTo)
+		}
+	}
+
+	// Update castle rights. Moving from or to a king or rook starting square
+	// forfeits the rights that depend on it.
+	p.CastleRights.Remove(castleRightsLost[m.From] | castleRightsLost[m.To])
+
+	// Update en passant settings.
+	p.EnPassantFlag, p.EnPassantSquare = false, 0
+	if piece.Role == Pawn && (m.To == m.From+16 || m.From == m.To+16) {
+		p.EnPassantFlag = true
+		p.EnPassantSquare = (m.From + m.To) / 2
+	}
+
+	// Update the clocks.
+	if piece.Role == Pawn || u.WasCapture {
+		p.HalfMoveClock = 0
+	} else if p.HalfMoveClock < math.MaxUint8 {
+		p.HalfMoveClock++
+	}
+
+	if p.SideToMove == Black {
+		p.FullMoveNumber++
+	}
+
+	p.SideToMove = p.SideToMove.Other()
+
+	p.hash ^= sideKey ^ p.enPassantKey() ^ castleKeys[p.CastleRights]
+
+	return u
+}
 
 // Undo undoes a [Position.Move] call.
 func (p *Position) Undo(u *Undo) {
-	// Move the piece back.
+	m := u.Move
+
+	p.SideToMove = p.SideToMove.Other()
+
+	if p.SideToMove == Black {
+		p.FullMoveNumber--
+	}
+
+	// Move the piece back, demoting it if necessary.
+	piece, _ := p.Board.At(m.To)
+	p.Board.Remove(m.To)
+	if m.PromotionInfo != NoPromotion {
+		piece.Role = Pawn
+	}
+	p.Board.PutDangerous(piece, m.From)
+
+	// Move the rook back when castling.
+	if piece.Role == King {
+		if c, ok := castleFor(m); ok {
+			p.Board.Remove(c.rookTo)
+			p.Board.PutDangerous(Piece{piece.Color, Rook}, c.rook)
+		}
+	}
 
 	// Restore the captured piece, if any.
 	if u.WasCapture {
-		capturedPiece := Piece{p.SideToMove, u.CapturedRole}
-		p.Board.Put(capturedPiece, u.Move.To)
+		captureSquare := m.To
+		if u.WasEnPassant {
+			captureSquare = SquareAt(m.To.File(), m.From.Rank())
+		}
+		capturedPiece := Piece{p.SideToMove.Other(), u.CapturedRole}
+		p.Board.PutDangerous(capturedPiece, captureSquare)
 	}
 
 	// Restore en passant settings.
@@ -60,6 +156,12 @@ func (p *Position) Undo(u *Undo) {
 
 	// Restore castling rights.
 	p.CastleRights = u.CastleRights
+
+	// Restore the half move clock.
+	p.HalfMoveClock = u.HalfMoveClock
+
+	// Restore the hash.
+	p.hash = u.Hash
 }
 
 // IsValid returns nil if the position is valid.
@@ -69,10 +171,15 @@ func (p *Position) IsValid() error {
 		return err
 	}
 
-	// The en passant flag and square must agree.
+	// The en passant flag and square must agree, and the square must be
+	// behind a pawn that the opponent just pushed. Otherwise Move would
+	// capture a pawn of the side to move.
 	if p.EnPassantFlag {
-		r := p.EnPassantSquare.Rank()
-		if r != 3 && r != 6 {
+		want := Rank6
+		if p.SideToMove == Black {
+			want = Rank3
+		}
+		if p.EnPassantSquare.Rank() != want {
 			return fmt.Errorf("invalid en passant square: %s", p.EnPassantSquare)
 		}
 	} else {
@@ -85,5 +192,39 @@ func (p *Position) IsValid() error {
 		return fmt.Errorf("invalid castling rights")
 	}
 
+	// The side not to move must not be in check.
+	them := p.SideToMove.Other()
+	if p.IsAttacked(p.Board.KingOf(them), p.SideToMove) {
+		return fmt.Errorf("%v is in check but not to move", them)
+	}
+
 	return nil
 }
+
+// IsAttacked returns true if any piece of the given color attacks the square.
+func (p *Position) IsAttacked(s Square, by Color) bool {
+	return p.Board.attackersTo(s, by, p.Board.Occupied()) != 0
+}
+
+// Checkers returns a bitboard of the pieces giving check to the side to move.
+func (p *Position) Checkers() Bitboard {
+	king := p.Board.KingOf(p.SideToMove)
+	return p.Board.attackersTo(king, p.SideToMove.Other(), p.Board.Occupied())
+}
+
+// InCheck returns true if the side to move is in check.
+func (p *Position) InCheck() bool {
+	return p.Checkers() != 0
+}
+
+// IsCheckmate returns true if the side to move is in check and has no legal
+// moves.
+func (p *Position) IsCheckmate() bool {
+	return p.InCheck() && len(p.LegalMoves()) == 0
+}
+
+// IsStalemate returns true if the side to move is not in check but has no
+// legal moves.
+func (p *Position) IsStalemate() bool {
+	return !p.InCheck() && len(p.LegalMoves()) == 0
+}
@@ -0,0 +1,189 @@
+package chess_test
+
+import (
+	"testing"
+
+	"github.com/clfs/aloe/chess"
+	"github.com/clfs/aloe/fen"
+)
+
+// checkMoveUndo makes and unmakes every move to the given depth, failing if
+// any Undo doesn't restore the position exactly.
+func checkMoveUndo(t *testing.T, p *chess.Position, depth int) {
+	t.Helper()
+
+	if depth == 0 {
+		return
+	}
+
+	for _, m := range p.LegalMoves() {
+		before := *p
+
+		u := p.Move(m)
+		checkMoveUndo(t, p, depth-1)
+		p.Undo(u)
+
+		if *p != before {
+			t.Fatalf("%v: position changed after Move then Undo", m)
+		}
+	}
+}
+
+func TestPosition_MoveUndo(t *testing.T) {
+	fens := []string{
+		fen.StartingFEN,
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3",
+	}
+	for _, s := range fens {
+		p, err := fen.Decode(s)
+		if err != nil {
+			t.Fatalf("%q: %v", s, err)
+		}
+		checkMoveUndo(t, &p, 3)
+	}
+}
+
+func TestPosition_Move(t *testing.T) {
+	cases := []struct {
+		fen  string
+		move string
+		want string
+	}{
+		{
+			fen.StartingFEN,
+			"e2e4",
+			"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+		},
+		{
+			"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3",
+			"e5f6",
+			"rnbqkbnr/ppp1p1pp/5P2/3p4/8/8/PPPP1PPP/RNBQKBNR b KQkq - 0 3",
+		},
+		{
+			"r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 5 20",
+			"e8c8",
+			"2kr3r/8/8/8/8/8/8/R3K2R w KQ - 6 21",
+		},
+		{
+			"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",
+			"a1a8",
+			"R3k2r/8/8/8/8/8/8/4K2R b Kk - 0 1",
+		},
+		{
+			"4k3/1P6/8/8/8/8/8/4K3 w - - 3 40",
+			"b7b8n",
+			"1N2k3/8/8/8/8/8/8/4K3 b - - 0 40",
+		},
+	}
+	for _, tc := range cases {
+		p, err := fen.Decode(tc.fen)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.fen, err)
+		}
+
+		m, err := chess.NewMove(tc.move)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.move, err)
+		}
+
+		p.Move(m)
+
+		got, err := fen.Encode(p)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.want, err)
+		}
+
+		if got != tc.want {
+			t.Errorf("%q: %s: got %q, want %q", tc.fen, tc.move, got, tc.want)
+		}
+	}
+}
+
+func TestPosition_CheckQueries(t *testing.T) {
+	cases := []struct {
+		fen       string
+		checkers  int
+		checkmate bool
+		stalemate bool
+	}{
+		{fen.StartingFEN, 0, false, false},
+		// Fool's mate.
+		{"rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3", 1, true, false},
+		// Double check, but the king can escape.
+		{"4k3/8/8/8/8/5n2/8/R3K2r w Q - 0 1", 2, false, false},
+		{"7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", 0, false, true},
+		{"7k/6Q1/6K1/8/8/8/8/8 b - - 0 1", 1, true, false},
+	}
+	for _, tc := range cases {
+		p, err := fen.Decode(tc.fen)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.fen, err)
+		}
+
+		checkers := p.Checkers()
+		if got := checkers.Count(); got != tc.checkers {
+			t.Errorf("%q: got %d checkers, want %d", tc.fen, got, tc.checkers)
+		}
+		if got, want := p.InCheck(), tc.checkers > 0; got != want {
+			t.Errorf("%q: InCheck: got %t, want %t", tc.fen, got, want)
+		}
+		if got := p.IsCheckmate(); got != tc.checkmate {
+			t.Errorf("%q: IsCheckmate: got %t, want %t", tc.fen, got, tc.checkmate)
+		}
+		if got := p.IsStalemate(); got != tc.stalemate {
+			t.Errorf("%q: IsStalemate: got %t, want %t", tc.fen, got, tc.stalemate)
+		}
+	}
+}
+
+func TestPosition_IsAttacked(t *testing.T) {
+	p := chess.NewPosition()
+
+	cases := []struct {
+		s    chess.Square
+		by   chess.Color
+		want bool
+	}{
+		{chess.F3, chess.White, true},
+		{chess.E4, chess.White, false},
+		{chess.E2, chess.White, true},
+		{chess.F6, chess.Black, true},
+		{chess.F3, chess.Black, false},
+	}
+	for _, tc := range cases {
+		if got := p.IsAttacked(tc.s, tc.by); got != tc.want {
+			t.Errorf("%v by %v: got %t, want %t", tc.s, tc.by, got, tc.want)
+		}
+	}
+}
+
+func TestPosition_IsValid(t *testing.T) {
+	cases := []struct {
+		fen     string
+		wantErr bool
+	}{
+		{fen.StartingFEN, false},
+		{"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1", false},
+		// The side not to move is in check.
+		{"rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR b KQkq - 1 3", true},
+		// Both kings are in check.
+		{"4k3/8/8/8/8/8/4R3/r3K3 w - - 0 1", true},
+		{"4k3/8/8/8/8/8/8/P3K3 w - - 0 1", true},
+		// The en passant square is behind a pawn of the side to move.
+		{"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR w KQkq e3 0 1", true},
+		{"rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e6 0 2", true},
+		{"rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR w KQkq e6 0 2", false},
+	}
+	for _, tc := range cases {
+		p, err := fen.Decode(tc.fen)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.fen, err)
+		}
+
+		if err := p.IsValid(); (err != nil) != tc.wantErr {
+			t.Errorf("%q: got error %v, want error %t", tc.fen, err, tc.wantErr)
+		}
+	}
+}
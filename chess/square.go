@@ -95,7 +95,8 @@ func (s Square) Bitboard() Bitboard {
 
 // IsAdjacentTo returns true if the square is adjacent to the other square.
 func (s Square) IsAdjacentTo(other Square) bool {
-	return false
+	bb := KingAttacks(s)
+	return bb.Get(other)
 }
 
 // parseSquare returns the square corresponding to a lowercase string, like "a1".
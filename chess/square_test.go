@@ -18,3 +18,23 @@ func TestSquare_String(t *testing.T) {
 		}
 	}
 }
+
+func TestSquare_IsAdjacentTo(t *testing.T) {
+	cases := []struct {
+		a, b Square
+		want bool
+	}{
+		{E4, E5, true},
+		{E4, D3, true},
+		{A1, B2, true},
+		{E4, E4, false},
+		{E4, E6, false},
+		{A1, H1, false},
+		{H1, A2, false},
+	}
+	for _, tc := range cases {
+		if got := tc.a.IsAdjacentTo(tc.b); got != tc.want {
+			t.Errorf("%v, %v: want %t, got %t", tc.a, tc.b, tc.want, got)
+		}
+	}
+}
@@ -5,10 +5,13 @@ type Undo struct {
 	Move Move // The move to undo.
 
 	WasCapture   bool // Was the move a capture?
+	WasEnPassant bool // Was the move an en passant capture?
 	CapturedRole Role // Role of the captured piece, if any.
 
 	EnPassantFlag   bool   // Was the move to undo preceded by a double pawn push?
 	EnPassantSquare Square // En passant square, if any.
 
-	CastleRights CastleRights // Previous castle rights.
+	CastleRights  CastleRights // Previous castle rights.
+	HalfMoveClock uint8        // Previous half move clock.
+	Hash          uint64       // Previous Zobrist hash.
 }
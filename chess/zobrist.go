@@ -0,0 +1,109 @@
+package chess
+
+// Zobrist keys. See https://www.chessprogramming.org/Zobrist_Hashing.
+var (
+	pieceKeys     [2][6][64]uint64 // Indexed by [Color.index], role and square.
+	sideKey       uint64           // Hashed in when Black is to move.
+	castleKeys    [16]uint64       // Indexed by castle rights.
+	enPassantKeys [8]uint64        // Indexed by en passant file.
+)
+
+func init() {
+	// The seed is fixed so that hashes are stable across runs.
+	rng := splitmix64(0x5EED)
+
+	for c := range pieceKeys {
+		for r := range pieceKeys[c] {
+			for s := range pieceKeys[c][r] {
+				pieceKeys[c][r][s] = rng.next()
+			}
+		}
+	}
+
+	sideKey = rng.next()
+
+	// Each castle right gets its own key, and a set of rights hashes to the
+	// XOR of its members' keys.
+	var rightKeys [4]uint64
+	for i := range rightKeys {
+		rightKeys[i] = rng.next()
+	}
+	for cr := range castleKeys {
+		for i := range rightKeys {
+			if cr&(1<<i) != 0 {
+				castleKeys[cr] ^= rightKeys[i]
+			}
+		}
+	}
+
+	for f := range enPassantKeys {
+		enPassantKeys[f] = rng.next()
+	}
+}
+
+// Hash returns the Zobrist hash of the position. Positions that are the same
+// for the purposes of repetition have the same hash.
+//
+// The hash is updated incrementally by [Position.Move] and [Position.Undo].
+// After modifying a position's fields directly, call [Position.ResetHash].
+func (p *Position) Hash() uint64 {
+	return p.hash
+}
+
+// ResetHash recomputes the position's hash from scratch.
+func (p *Position) ResetHash() {
+	p.hash = p.ComputeHash()
+}
+
+// ComputeHash computes the Zobrist hash of the position from scratch. For a
+// position only modified by [Position.Move] and [Position.Undo], it always
+// equals [Position.Hash].
+func (p *Position) ComputeHash() uint64 {
+	var h uint64
+
+	for bb := p.Board.Occupied(); bb != 0; {
+		s := bb.Pop()
+		piece, _ := p.Board.At(s)
+		h ^= pieceKey(piece, s)
+	}
+
+	if p.SideToMove == Black {
+		h ^= sideKey
+	}
+
+	return h ^ castleKeys[p.CastleRights] ^ p.enPassantKey()
+}
+
+// pieceKey returns the Zobrist key for a piece on a square.
+func pieceKey(piece Piece, s Square) uint64 {
+	return pieceKeys[piece.Color.index()][piece.Role][s]
+}
+
+// enPassantKey returns the Zobrist key for the en passant square. The file is
+// only hashed when a pawn of the side to move is in place to capture en
+// passant, so that a double pawn push with no possible reply doesn't prevent
+// a repetition.
+func (p *Position) enPassantKey() uint64 {
+	if !p.EnPassantFlag {
+		return 0
+	}
+
+	us := p.SideToMove
+	capturers := PawnAttacks(us.Other(), p.EnPassantSquare) & p.Board.pawns & p.Board.ByColor(us)
+	if capturers == 0 {
+		return 0
+	}
+
+	return enPassantKeys[p.EnPassantSquare.File()]
+}
+
+// splitmix64 is a SplitMix64 pseudorandom number generator.
+type splitmix64 uint64
+
+func (x *splitmix64) next() uint64 {
+	*x += 0x9E3779B97F4A7C15
+	z := uint64(*x)
+	z = (z ^ z>>30) * 0xBF58476D1CE4E5B9
+	z = (z ^ z>>27) * 0x94D049BB133111EB
+	return z ^ z>>31
+}
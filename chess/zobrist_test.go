@@ -0,0 +1,122 @@
+package chess_test
+
+import (
+	"testing"
+
+	"github.com/clfs/aloe/chess"
+	"github.com/clfs/aloe/fen"
+)
+
+// checkHash walks the move tree to the given depth, failing if the
+// incrementally updated hash ever differs from a recomputed one.
+func checkHash(t *testing.T, p *chess.Position, depth int) {
+	t.Helper()
+
+	if got, want := p.Hash(), p.ComputeHash(); got != want {
+		s, _ := fen.Encode(*p)
+		t.Fatalf("%q: got hash %#x, want %#x", s, got, want)
+	}
+
+	if depth == 0 {
+		return
+	}
+
+	for _, m := range p.LegalMoves() {
+		u := p.Move(m)
+		checkHash(t, p, depth-1)
+		p.Undo(u)
+	}
+}
+
+func TestPosition_Hash(t *testing.T) {
+	fens := []string{
+		fen.StartingFEN,
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+	}
+	for _, s := range fens {
+		p, err := fen.Decode(s)
+		if err != nil {
+			t.Fatalf("%q: %v", s, err)
+		}
+		checkHash(t, &p, 3)
+	}
+}
+
+func TestPosition_Hash_Transposition(t *testing.T) {
+	play := func(moves ...string) chess.Position {
+		p := chess.NewPosition()
+		for _, s := range moves {
+			m, err := chess.NewMove(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p.Move(m)
+		}
+		return p
+	}
+
+	cases := []struct {
+		a, b []string
+		same bool
+	}{
+		{
+			a:    []string{"g1f3", "g8f6", "b1c3"},
+			b:    []string{"b1c3", "g8f6", "g1f3"},
+			same: true,
+		},
+		{
+			// Castle rights differ.
+			a:    []string{"g1f3", "g8f6", "f3g1", "f6g8"},
+			b:    []string{"h2h3", "g8f6", "h1h2", "f6g8", "h2h1", "b8c6", "h3h2", "c6b8"},
+			same: false,
+		},
+		{
+			// Move counters are not hashed.
+			a:    []string{"g1f3"},
+			b:    []string{"g1h3", "b8c6", "h3g1", "c6b8", "g1f3", "g8f6", "f3g1", "f6g8", "g1f3"},
+			same: true,
+		},
+	}
+	for i, tc := range cases {
+		a, b := play(tc.a...), play(tc.b...)
+		if got := a.Hash() == b.Hash(); got != tc.same {
+			t.Errorf("%d: got same hash %t, want %t", i, got, tc.same)
+		}
+	}
+}
+
+func TestPosition_Hash_EnPassant(t *testing.T) {
+	cases := []struct {
+		a, b string
+		same bool
+	}{
+		{
+			// White can't capture en passant, so the square doesn't matter.
+			a:    "rnbqkbnr/pppp1ppp/8/4p3/8/5N2/PPPPPPPP/RNBQKB1R w KQkq e6 0 2",
+			b:    "rnbqkbnr/pppp1ppp/8/4p3/8/5N2/PPPPPPPP/RNBQKB1R w KQkq - 0 2",
+			same: true,
+		},
+		{
+			a:    "rnbqkbnr/pppp1ppp/8/3Pp3/8/8/PPP1PPPP/RNBQKBNR w KQkq e6 0 3",
+			b:    "rnbqkbnr/pppp1ppp/8/3Pp3/8/8/PPP1PPPP/RNBQKBNR w KQkq - 0 3",
+			same: false,
+		},
+	}
+	for i, tc := range cases {
+		a, err := fen.Decode(tc.a)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.a, err)
+		}
+
+		b, err := fen.Decode(tc.b)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.b, err)
+		}
+
+		if got := a.Hash() == b.Hash(); got != tc.same {
+			t.Errorf("%d: got same hash %t, want %t", i, got, tc.same)
+		}
+	}
+}
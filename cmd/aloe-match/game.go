@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/clfs/aloe/chess"
+	"github.com/clfs/aloe/pgn"
+	"github.com/clfs/aloe/uci"
+)
+
+// PGN Termination tag values.
+const (
+	terminationNormal        = "normal"
+	terminationTimeForfeit   = "time forfeit"
+	terminationIllegalMove   = "rules infraction"
+	terminationEngineFailure = "abandoned"
+)
+
+// outcome is how a game ended.
+type outcome struct {
+	result      string // A PGN game termination marker.
+	termination string // A PGN Termination tag value.
+	reason      string // A description, like "White mates".
+}
+
+// adjudicate returns the outcome of a game if it's over by the rules of
+// chess. Draws that a player could claim are adjudicated as draws.
+func adjudicate(g *chess.Game) (outcome, bool) {
+	p := g.Position()
+
+	switch {
+	case p.IsCheckmate():
+		winner := p.SideToMove.Other()
+		return outcome{wins(winner), terminationNormal, winner.String() + " mates"}, true
+	case p.IsStalemate():
+		return outcome{pgn.Draw, terminationNormal, "Draw by stalemate"}, true
+	case p.HasInsufficientMaterial():
+		return outcome{pgn.Draw, terminationNormal, "Draw by insufficient material"}, true
+	case g.IsThreefoldRepetition():
+		return outcome{pgn.Draw, terminationNormal, "Draw by threefold repetition"}, true
+	case p.IsFiftyMoveDraw():
+		return outcome{pgn.Draw, terminationNormal, "Draw by fifty-move rule"}, true
+	}
+
+	return outcome{}, false
+}
+
+// forfeit returns the outcome of a game that loser forfeits.
+func forfeit(loser chess.Color, termination, reason string) outcome {
+	return outcome{wins(loser.Other()), termination, reason}
+}
+
+// wins returns the PGN game termination marker for a win by c.
+func wins(c chess.Color) string {
+	if c == chess.White {
+		return pgn.WhiteWins
+	}
+	return pgn.BlackWins
+}
+
+// match holds the settings shared by the games of a match.
+type match struct {
+	event   string
+	tc      timeControl
+	timeout time.Duration // How long to wait for a move if there's no clock.
+}
+
+// play plays a game from an opening. Engine failures lose the game rather
+// than return an error; errors are only returned if an engine can't be
+// (re)started, or if ctx is done.
+func (m *match) play(ctx context.Context, white, black *player, o opening, round int) (*pgn.Game, error) {
+	for _, p := range []*player{white, black} {
+		if err := p.newGame(ctx); err != nil {
+			return nil, fmt.Errorf("%s: %w", p.name, err)
+		}
+	}
+
+	pg, err := pgn.NewGame(o.start)
+	if err != nil {
+		return nil, err
+	}
+	pg.SetTag("Event", m.event)
+	pg.SetTag("Date", time.Now().Format("2006.01.02"))
+	pg.SetTag("Round", strconv.Itoa(round))
+	pg.SetTag("White", white.name)
+	pg.SetTag("Black", black.name)
+	pg.SetTag("TimeControl", m.tc.String())
+
+	g := chess.NewGame(o.start)
+	node := pg.Root
+	for _, mv := range o.moves {
+		g.Move(mv)
+		node = node.AddMove(mv)
+	}
+
+	players := map[chess.Color]*player{chess.White: white, chess.Black: black}
+	clocks := map[chess.Color]*clock{chess.White: newClock(m.tc), chess.Black: newClock(m.tc)}
+
+	var out outcome
+	for {
+		var over bool
+		if out, over = adjudicate(g); over {
+			break
+		}
+
+		pos := g.Position()
+		side := pos.SideToMove
+		p, c := players[side], clocks[side]
+
+		timeout := m.timeout
+		if m.tc.hasClock() {
+			timeout = c.deadline()
+		}
+
+		req := m.tc.goRequest(side, clocks[chess.White], clocks[chess.Black])
+		mv, elapsed, err := search(ctx, p, &uci.Position{Start: o.start, Moves: g.Moves()}, req, timeout)
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			out = forfeit(side, terminationTimeForfeit, side.String()+" loses on time")
+			break
+		}
+		if err != nil {
+			log.Printf("%s: %v", p.name, err)
+			p.stop()
+			out = forfeit(side, terminationEngineFailure, fmt.Sprintf("%s's engine fails: %v", side, err))
+			break
+		}
+		// Only a move that arrived is charged to the clock.
+		if m.tc.hasClock() && !c.punch(elapsed) {
+			out = forfeit(side, terminationTimeForfeit, side.String()+" loses on time")
+			break
+		}
+		if !pos.IsLegalMove(mv) {
+			out = forfeit(side, terminationIllegalMove, fmt.Sprintf("%s makes an illegal move: %s", side, mv.LAN()))
+			break
+		}
+
+		g.Move(mv)
+		node = node.AddMove(mv)
+	}
+
+	pg.Result = out.result
+	pg.SetTag("Termination", out.termination)
+	node.Comments = append(node.Comments, out.reason)
+
+	return pg, nil
+}
+
+// search asks p for a move in a position, waiting at most timeout.
+func search(ctx context.Context, p *player, pos *uci.Position, req *uci.Go, timeout time.Duration) (chess.Move, time.Duration, error) {
+	if err := p.c.SetPosition(pos); err != nil {
+		return chess.Move{}, 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	best, err := p.c.Go(ctx, req, nil)
+	elapsed := time.Since(start)
+	if err != nil {
+		return chess.Move{}, elapsed, err
+	}
+
+	return best.Move, elapsed, nil
+}
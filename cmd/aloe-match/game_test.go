@@ -0,0 +1,352 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/clfs/aloe/chess"
+	ucienc "github.com/clfs/aloe/encoding/uci"
+	"github.com/clfs/aloe/engine"
+	"github.com/clfs/aloe/fen"
+	"github.com/clfs/aloe/pgn"
+	"github.com/clfs/aloe/uci"
+	"github.com/clfs/aloe/uci/client"
+)
+
+// pipe is one end of an in-memory connection to an engine.
+type pipe struct {
+	*io.PipeReader
+	w *io.PipeWriter
+}
+
+func (p pipe) Write(b []byte) (int, error) { return p.w.Write(b) }
+func (p pipe) Close() error                { return p.w.Close() }
+
+// connect returns a client for an engine that runs serve on the other end of
+// an in-memory connection.
+func connect(serve func(r io.Reader, w io.WriteCloser)) *client.Client {
+	reqR, reqW := io.Pipe()
+	respR, respW := io.Pipe()
+	go func() {
+		serve(reqR, respW)
+		// Like a process's standard input, writes fail once it exits.
+		reqR.Close()
+	}()
+	return client.New(pipe{respR, reqW})
+}
+
+// aloe starts an in-process Aloe engine.
+func aloe() (*client.Client, error) {
+	return connect(func(r io.Reader, w io.WriteCloser) {
+		eng := engine.New()
+
+		go func() {
+			defer w.Close()
+			enc := ucienc.NewEncoder(w)
+			for {
+				resp, err := eng.Respond()
+				if err != nil {
+					return
+				}
+				enc.Encode(resp)
+			}
+		}()
+
+		dec := ucienc.NewDecoder(r)
+		for {
+			req, err := dec.Request()
+			if err != nil || eng.Do(req) != nil {
+				eng.Close()
+				return
+			}
+		}
+	}), nil
+}
+
+// fake starts a stand-in engine that plays the move chosen by play. If play
+// reports false, the engine exits instead.
+func fake(play func(p chess.Position) (chess.Move, bool)) func() (*client.Client, error) {
+	return func() (*client.Client, error) {
+		return connect(func(r io.Reader, w io.WriteCloser) {
+			defer w.Close()
+			dec, enc := ucienc.NewDecoder(r), ucienc.NewEncoder(w)
+
+			var pos chess.Position
+			for {
+				req, err := dec.Request()
+				if err != nil {
+					return
+				}
+
+				switch req := req.(type) {
+				case *uci.UCI:
+					enc.Encode(&uci.ID{Name: "Fake"})
+					enc.Encode(&uci.UCIOk{})
+				case *uci.IsReady:
+					enc.Encode(&uci.ReadyOk{})
+				case *uci.Position:
+					pos = req.Start
+					for _, m := range req.Moves {
+						pos.Move(m)
+					}
+				case *uci.Go:
+					m, ok := play(pos)
+					if !ok {
+						return
+					}
+					enc.Encode(&uci.BestMove{Move: m})
+				case *uci.Quit:
+					return
+				}
+			}
+		}), nil
+	}
+}
+
+// firstMove plays the first legal move.
+func firstMove(p chess.Position) (chess.Move, bool) {
+	return p.LegalMoves()[0], true
+}
+
+func mustDecode(t *testing.T, s string) chess.Position {
+	t.Helper()
+	p, err := fen.Decode(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func mustMoves(t *testing.T, lans ...string) []chess.Move {
+	t.Helper()
+	var moves []chess.Move
+	for _, s := range lans {
+		m, err := chess.NewMove(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		moves = append(moves, m)
+	}
+	return moves
+}
+
+func TestAdjudicate(t *testing.T) {
+	cases := []struct {
+		name   string
+		fen    string
+		moves  []string
+		over   bool
+		result string
+		reason string
+	}{
+		{
+			name: "in progress",
+			fen:  fen.StartingFEN,
+		},
+		{
+			name:   "checkmate",
+			fen:    fen.StartingFEN,
+			moves:  []string{"f2f3", "e7e5", "g2g4", "d8h4"},
+			over:   true,
+			result: pgn.BlackWins,
+			reason: "Black mates",
+		},
+		{
+			name:   "stalemate",
+			fen:    "7k/5Q2/6K1/8/8/8/8/8 b - - 0 1",
+			over:   true,
+			result: pgn.Draw,
+			reason: "Draw by stalemate",
+		},
+		{
+			name:   "insufficient material",
+			fen:    "8/8/4k3/8/8/3NK3/8/8 w - - 0 1",
+			over:   true,
+			result: pgn.Draw,
+			reason: "Draw by insufficient material",
+		},
+		{
+			name:   "threefold repetition",
+			fen:    fen.StartingFEN,
+			moves:  []string{"g1f3", "g8f6", "f3g1", "f6g8", "g1f3", "g8f6", "f3g1", "f6g8"},
+			over:   true,
+			result: pgn.Draw,
+			reason: "Draw by threefold repetition",
+		},
+		{
+			name:   "fifty-move rule",
+			fen:    "8/8/4k3/8/8/3RK3/8/8 w - - 100 80",
+			over:   true,
+			result: pgn.Draw,
+			reason: "Draw by fifty-move rule",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := chess.NewGame(mustDecode(t, tc.fen))
+			for _, m := range mustMoves(t, tc.moves...) {
+				g.Move(m)
+			}
+
+			out, over := adjudicate(g)
+			if over != tc.over {
+				t.Fatalf("got over %t, want %t", over, tc.over)
+			}
+			if out.result != tc.result || out.reason != tc.reason {
+				t.Errorf("got %q %q, want %q %q", out.result, out.reason, tc.result, tc.reason)
+			}
+		})
+	}
+}
+
+func TestMatch_Play(t *testing.T) {
+	white := &player{name: "White", start: aloe}
+	black := &player{name: "Black", start: aloe}
+	defer white.stop()
+	defer black.stop()
+
+	m := &match{event: "Test", tc: timeControl{depth: 2}, timeout: time.Minute}
+	o := opening{
+		start: mustDecode(t, "3k4/8/4K3/8/8/8/8/Q7 b - - 0 1"),
+		moves: mustMoves(t, "d8e8"),
+	}
+
+	g, err := m.play(context.Background(), white, black, o, 1)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if g.Result != pgn.WhiteWins {
+		t.Errorf("got result %q, want %q", g.Result, pgn.WhiteWins)
+	}
+	if got, _ := g.Tag("Termination"); got != terminationNormal {
+		t.Errorf("got termination %q", got)
+	}
+
+	// The game survives a round trip through PGN, opening moves included.
+	var buf bytes.Buffer
+	if err := pgn.NewWriter(&buf).Write(g); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	got, err := pgn.NewReader(&buf).Read()
+	if err != nil {
+		t.Fatalf("read error: %v", err)
+	}
+	if len(got.MainLine()) != len(g.MainLine()) || got.MainLine()[0] != o.moves[0] {
+		t.Errorf("got main line %v, want %v", got.MainLine(), g.MainLine())
+	}
+	if got.Result != g.Result {
+		t.Errorf("got result %q after round trip, want %q", got.Result, g.Result)
+	}
+}
+
+func TestMatch_Play_Forfeits(t *testing.T) {
+	slow := func(p chess.Position) (chess.Move, bool) {
+		time.Sleep(200 * time.Millisecond)
+		return firstMove(p)
+	}
+	illegal := func(chess.Position) (chess.Move, bool) {
+		return chess.Move{}, true
+	}
+	crash := func(chess.Position) (chess.Move, bool) {
+		return chess.Move{}, false
+	}
+
+	cases := []struct {
+		name        string
+		play        func(chess.Position) (chess.Move, bool)
+		tc          timeControl
+		termination string
+		reason      string
+	}{
+		{
+			name:        "time",
+			play:        slow,
+			tc:          timeControl{base: 50 * time.Millisecond},
+			termination: terminationTimeForfeit,
+			reason:      "Black loses on time",
+		},
+		{
+			name:        "illegal move",
+			play:        illegal,
+			tc:          timeControl{depth: 1},
+			termination: terminationIllegalMove,
+			reason:      "Black makes an illegal move: 0000",
+		},
+		{
+			name:        "crash",
+			play:        crash,
+			tc:          timeControl{depth: 1},
+			termination: terminationEngineFailure,
+			reason:      "Black's engine fails: engine output ended: unexpected EOF",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			white := &player{name: "White", start: fake(firstMove)}
+			black := &player{name: "Black", start: fake(tc.play)}
+			defer white.stop()
+			defer black.stop()
+
+			m := &match{tc: tc.tc, timeout: time.Minute}
+			g, err := m.play(context.Background(), white, black, opening{start: chess.NewPosition()}, 1)
+			if err != nil {
+				t.Fatalf("error: %v", err)
+			}
+
+			if g.Result != pgn.WhiteWins {
+				t.Errorf("got result %q, want %q", g.Result, pgn.WhiteWins)
+			}
+			if got, _ := g.Tag("Termination"); got != tc.termination {
+				t.Errorf("got termination %q, want %q", got, tc.termination)
+			}
+			if got := reason(g); got != tc.reason {
+				t.Errorf("got reason %q, want %q", got, tc.reason)
+			}
+		})
+	}
+}
+
+func TestMatch_Play_Restart(t *testing.T) {
+	starts := 0
+	crashy := &player{name: "Crashy", start: func() (*client.Client, error) {
+		starts++
+		return fake(func(chess.Position) (chess.Move, bool) { return chess.Move{}, false })()
+	}}
+	other := &player{name: "Other", start: fake(firstMove)}
+	defer crashy.stop()
+	defer other.stop()
+
+	m := &match{tc: timeControl{depth: 1}, timeout: time.Minute}
+	for i := 0; i < 2; i++ {
+		if _, err := m.play(context.Background(), other, crashy, opening{start: chess.NewPosition()}, i+1); err != nil {
+			t.Fatalf("game %d: error: %v", i+1, err)
+		}
+	}
+
+	if starts != 2 {
+		t.Errorf("engine started %d times, want 2", starts)
+	}
+}
+
+func TestMatch_Play_Canceled(t *testing.T) {
+	white := &player{name: "White", start: fake(firstMove)}
+	black := &player{name: "Black", start: fake(firstMove)}
+	defer white.stop()
+	defer black.stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	m := &match{tc: timeControl{depth: 1}, timeout: time.Minute}
+	_, err := m.play(ctx, white, black, opening{start: chess.NewPosition()}, 1)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+}
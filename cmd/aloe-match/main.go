@@ -0,0 +1,273 @@
+// Aloe-match plays games between two UCI engines.
+//
+// Usage:
+//
+//	aloe-match [flags] engine1 engine2
+//
+// Each engine is a command line, like "stockfish" or "./aloe-old". The engines
+// alternate colors, and each opening is played once with each engine as
+// White. Openings are read from a file of FENs, one per line, or from a PGN
+// file, in which case the main line of every game is an opening. Without an
+// opening file, games start from the standard starting position.
+//
+// At least one of -depth, -nodes and -tc limits each search. The -tc flag
+// sets a clock in the form [moves/]base[+inc], with times in seconds: "10+0.1"
+// is 10 seconds per game plus 0.1 seconds per move, and "40/60" is 60 seconds
+// for every 40 moves.
+//
+// Games are adjudicated by the rules of chess, with draws that could be
+// claimed scored as draws. An engine that runs out of time, plays an illegal
+// move or crashes loses the game, and a crashed engine is restarted. Every game
+// is written in PGN to the file named by -pgn, or to standard output.
+//
+// After every game, the score, the Elo difference with a 95% confidence
+// interval and the likelihood of superiority of the first engine are logged.
+// Once there are complete game pairs, they're computed from the pairs, which
+// is more accurate. With -sprt, a sequential probability ratio test of
+// -elo0 against -elo1 is checked after every pair, and the match stops early
+// once it accepts either; -games is then the most games to play.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/clfs/aloe/chess"
+	"github.com/clfs/aloe/pgn"
+	"github.com/clfs/aloe/stats"
+	"github.com/clfs/aloe/uci/client"
+)
+
+// confidence is the confidence level of reported Elo intervals.
+const confidence = 0.95
+
+// optionsFlag is a repeatable flag of engine options.
+type optionsFlag []option
+
+func (f *optionsFlag) String() string {
+	var s []string
+	for _, o := range *f {
+		s = append(s, o.name+"="+o.value)
+	}
+	return strings.Join(s, ",")
+}
+
+func (f *optionsFlag) Set(s string) error {
+	o, err := parseOption(s)
+	if err != nil {
+		return err
+	}
+	*f = append(*f, o)
+	return nil
+}
+
+var (
+	gamesFlag    = flag.Int("games", 2, "number of games to play")
+	openingsFlag = flag.String("openings", "", "file of openings, as FENs or PGN")
+	depthFlag    = flag.Int("depth", 0, "search depth limit")
+	nodesFlag    = flag.Int("nodes", 0, "search node limit")
+	tcFlag       = flag.String("tc", "", "clock time control, as [moves/]base[+inc] in seconds")
+	marginFlag   = flag.Duration("margin", 0, "how far an engine may overrun its clock")
+	timeoutFlag  = flag.Duration("timeout", time.Minute, "how long to wait for a move without a clock")
+	pgnFlag      = flag.String("pgn", "", "file to append games to (default standard output)")
+	eventFlag    = flag.String("event", "?", "event name for PGN")
+	name1Flag    = flag.String("name1", "", "name of the first engine (default from the engine)")
+	name2Flag    = flag.String("name2", "", "name of the second engine (default from the engine)")
+	sprtFlag     = flag.Bool("sprt", false, "stop once the SPRT accepts either hypothesis")
+	elo0Flag     = flag.Float64("elo0", 0, "SPRT Elo difference under H0")
+	elo1Flag     = flag.Float64("elo1", 5, "SPRT Elo difference under H1")
+	alphaFlag    = flag.Float64("alpha", 0.05, "SPRT probability of a false positive")
+	betaFlag     = flag.Float64("beta", 0.05, "SPRT probability of a false negative")
+
+	options1Flag optionsFlag
+	options2Flag optionsFlag
+)
+
+func init() {
+	flag.Var(&options1Flag, "o1", "option `name=value` for the first engine (repeatable)")
+	flag.Var(&options2Flag, "o2", "option `name=value` for the second engine (repeatable)")
+}
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "usage: aloe-match [flags] engine1 engine2\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("aloe-match: ")
+
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() != 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	tc := timeControl{depth: *depthFlag, nodes: *nodesFlag, margin: *marginFlag}
+	if *tcFlag != "" {
+		var err error
+		if tc.moves, tc.base, tc.inc, err = parseClock(*tcFlag); err != nil {
+			return err
+		}
+	}
+	if tc.depth <= 0 && tc.nodes <= 0 && !tc.hasClock() {
+		return errors.New("no search limit: use -depth, -nodes or -tc")
+	}
+
+	sprt := stats.SPRT{Elo0: *elo0Flag, Elo1: *elo1Flag, Alpha: *alphaFlag, Beta: *betaFlag}
+	if *sprtFlag {
+		if err := sprt.Validate(); err != nil {
+			return err
+		}
+	}
+
+	openings := []opening{{start: chess.NewPosition()}}
+	if *openingsFlag != "" {
+		var err error
+		if openings, err = readOpenings(*openingsFlag); err != nil {
+			return err
+		}
+	}
+
+	out := os.Stdout
+	if *pgnFlag != "" {
+		f, err := os.OpenFile(*pgnFlag, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	players := [2]*player{
+		newPlayer(*name1Flag, flag.Arg(0), options1Flag),
+		newPlayer(*name2Flag, flag.Arg(1), options2Flag),
+	}
+	for i, p := range players {
+		defer p.stop()
+		if err := p.restart(ctx); err != nil {
+			return fmt.Errorf("%s: %w", flag.Arg(i), err)
+		}
+		if p.name == "" {
+			p.name = flag.Arg(i)
+		}
+	}
+	if players[0].name == players[1].name {
+		players[0].name += " (1)"
+		players[1].name += " (2)"
+	}
+
+	m := &match{event: *eventFlag, tc: tc, timeout: *timeoutFlag}
+	w := pgn.NewWriter(out)
+
+	var (
+		games stats.Trinomial
+		pairs stats.Pentanomial
+		first stats.Result // The first result of the current game pair.
+	)
+	for i := 0; i < *gamesFlag; i++ {
+		white, black := players[0], players[1]
+		if i%2 == 1 {
+			white, black = black, white
+		}
+
+		g, err := m.play(ctx, white, black, openings[i/2%len(openings)], i+1)
+		if err != nil {
+			return err
+		}
+		if err := w.Write(g); err != nil {
+			return err
+		}
+
+		r := firstResult(g.Result, i%2 == 0)
+		games.Add(r)
+		if i%2 == 0 {
+			first = r
+		} else {
+			pairs.Add(first, r)
+		}
+
+		log.Printf("game %d: %s vs %s: %s {%s}", i+1, white.name, black.name, g.Result, reason(g))
+		log.Printf("score of %s vs %s: %s", players[0].name, players[1].name, formatScore(games))
+
+		var results stats.Results = games
+		if pairs.Pairs() > 0 {
+			results = pairs
+		}
+		e := stats.Elo(results, confidence)
+		log.Printf("elo: %.1f [%.1f, %.1f], los: %.1f%%", e.Elo, e.Lower, e.Upper, 100*stats.LOS(results))
+
+		if *sprtFlag && i%2 == 1 {
+			lower, upper := sprt.Bounds()
+			d := sprt.Test(pairs)
+			log.Printf("sprt: llr %.2f [%.2f, %.2f], %s", sprt.LLR(pairs), lower, upper, d)
+			if d != stats.Continue {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// reason returns the comment that ends a game played by [match.play].
+func reason(g *pgn.Game) string {
+	c := g.End().Comments
+	return c[len(c)-1]
+}
+
+// firstResult returns the result of a game for the first engine, given the
+// PGN result and whether the first engine played White.
+func firstResult(result string, firstIsWhite bool) stats.Result {
+	switch {
+	case result == pgn.Draw:
+		return stats.Draw
+	case (result == pgn.WhiteWins) == firstIsWhite:
+		return stats.Win
+	}
+	return stats.Loss
+}
+
+// formatScore formats a score like "wins - losses - draws [points per game]".
+func formatScore(t stats.Trinomial) string {
+	points := 0.0
+	if n := t.Games(); n > 0 {
+		points = (float64(t.Wins) + float64(t.Draws)/2) / float64(n)
+	}
+	return fmt.Sprintf("%d - %d - %d [%.3f]", t.Wins, t.Losses, t.Draws, points)
+}
+
+// newPlayer returns a player for an engine command line, like "stockfish". If
+// name is empty, the engine's name is used.
+func newPlayer(name, command string, options []option) *player {
+	args := strings.Fields(command)
+
+	return &player{
+		start: func() (*client.Client, error) {
+			if len(args) == 0 {
+				return nil, errors.New("empty engine command")
+			}
+			return client.Start(exec.Command(args[0], args[1:]...))
+		},
+		name:    name,
+		options: options,
+	}
+}
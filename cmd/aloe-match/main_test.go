@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/clfs/aloe/pgn"
+	"github.com/clfs/aloe/stats"
+)
+
+func TestFirstResult(t *testing.T) {
+	cases := []struct {
+		result       string
+		firstIsWhite bool
+		want         stats.Result
+	}{
+		{pgn.WhiteWins, true, stats.Win},
+		{pgn.WhiteWins, false, stats.Loss},
+		{pgn.BlackWins, true, stats.Loss},
+		{pgn.BlackWins, false, stats.Win},
+		{pgn.Draw, true, stats.Draw},
+		{pgn.Draw, false, stats.Draw},
+	}
+
+	for _, tc := range cases {
+		if got := firstResult(tc.result, tc.firstIsWhite); got != tc.want {
+			t.Errorf("firstResult(%q, %t): got %v, want %v", tc.result, tc.firstIsWhite, got, tc.want)
+		}
+	}
+}
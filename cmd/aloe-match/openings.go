@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/clfs/aloe/chess"
+	"github.com/clfs/aloe/fen"
+	"github.com/clfs/aloe/pgn"
+)
+
+// opening is a position to start games from, along with the moves that led to
+// it from a known position.
+type opening struct {
+	start chess.Position
+	moves []chess.Move
+}
+
+// readOpenings reads an opening suite. Files ending in ".pgn" hold games,
+// whose main lines are the openings. Other files hold one FEN per line; blank
+// lines and lines starting with "#" are skipped.
+func readOpenings(name string) ([]opening, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var openings []opening
+	if strings.EqualFold(filepath.Ext(name), ".pgn") {
+		openings, err = readPGNOpenings(f)
+	} else {
+		openings, err = readFENOpenings(f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	if len(openings) == 0 {
+		return nil, fmt.Errorf("%s: no openings", name)
+	}
+	return openings, nil
+}
+
+// readPGNOpenings reads the main line of every game in r.
+func readPGNOpenings(r io.Reader) ([]opening, error) {
+	var openings []opening
+
+	pr := pgn.NewReader(r)
+	for {
+		g, err := pr.Read()
+		if errors.Is(err, io.EOF) {
+			return openings, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		openings = append(openings, opening{
+			start: g.Root.Position,
+			moves: g.MainLine(),
+		})
+	}
+}
+
+// readFENOpenings reads one FEN per line from r.
+func readFENOpenings(r io.Reader) ([]opening, error) {
+	var openings []opening
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		p, err := fen.Decode(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		if err := p.IsValid(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+
+		openings = append(openings, opening{start: p})
+	}
+
+	return openings, scanner.Err()
+}
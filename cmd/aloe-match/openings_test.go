@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/clfs/aloe/chess"
+	"github.com/clfs/aloe/fen"
+)
+
+func writeFile(t *testing.T, name, contents string) string {
+	t.Helper()
+	name = filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(name, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func TestReadOpenings_FEN(t *testing.T) {
+	name := writeFile(t, "suite.epd", `# A comment.
+`+fen.StartingFEN+`
+
+8/8/4k3/8/8/3RK3/8/8 w - - 0 1
+`)
+
+	openings, err := readOpenings(name)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if len(openings) != 2 {
+		t.Fatalf("got %d openings, want 2", len(openings))
+	}
+	if openings[0].start != chess.NewPosition() || len(openings[0].moves) != 0 {
+		t.Errorf("got opening %v", openings[0])
+	}
+}
+
+func TestReadOpenings_PGN(t *testing.T) {
+	name := writeFile(t, "suite.pgn", `[Event "?"]
+
+1. e4 e5 2. Nf3 (2. f4) Nc6 *
+
+[FEN "8/8/4k3/8/8/3RK3/8/8 w - - 0 1"]
+[SetUp "1"]
+
+1. Rd1 *
+`)
+
+	openings, err := readOpenings(name)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if len(openings) != 2 {
+		t.Fatalf("got %d openings, want 2", len(openings))
+	}
+	if got := len(openings[0].moves); got != 4 {
+		t.Errorf("got %d moves in the first opening, want 4", got)
+	}
+	if got := len(openings[1].moves); got != 1 {
+		t.Errorf("got %d moves in the second opening, want 1", got)
+	}
+	if openings[1].start == chess.NewPosition() {
+		t.Error("second opening ignores its FEN tag")
+	}
+}
+
+func TestReadOpenings_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"empty.fen":   "# Nothing here.\n",
+		"bad.fen":     "not a fen\n",
+		"invalid.fen": "8/8/8/8/8/8/8/8 w - - 0 1\n",
+		"bad.pgn":     "1. e5 *\n",
+	}
+
+	for file, contents := range cases {
+		if _, err := readOpenings(writeFile(t, file, contents)); err == nil {
+			t.Errorf("%s: no error", file)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/clfs/aloe/uci/client"
+)
+
+// readyTimeout is how long an engine may take to start or to get ready for a
+// new game.
+const readyTimeout = 30 * time.Second
+
+// option is an engine option to set before play, like Hash=64.
+type option struct {
+	name, value string
+}
+
+// parseOption parses an option of the form name=value.
+func parseOption(s string) (option, error) {
+	name, value, ok := strings.Cut(s, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return option{}, fmt.Errorf("invalid option %q: want name=value", s)
+	}
+	return option{name, strings.TrimSpace(value)}, nil
+}
+
+// player is an engine taking part in a match. If the engine crashes or stops
+// responding, it's restarted before the next game.
+type player struct {
+	name    string                         // Used in PGN tags. Set from the engine if empty.
+	start   func() (*client.Client, error) // Starts the engine.
+	options []option
+
+	c *client.Client // The running engine, or nil.
+}
+
+// newGame gets the engine ready for a new game, starting it if needed.
+func (p *player) newGame(ctx context.Context) error {
+	if p.c != nil {
+		rctx, cancel := context.WithTimeout(ctx, readyTimeout)
+		err := p.c.NewGame(rctx)
+		cancel()
+
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		log.Printf("%s: %v; restarting", p.name, err)
+		p.stop()
+	}
+
+	return p.restart(ctx)
+}
+
+// restart starts the engine, shakes hands and sets its options.
+func (p *player) restart(ctx context.Context) error {
+	c, err := p.start()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readyTimeout)
+	defer cancel()
+
+	if err := p.setup(ctx, c); err != nil {
+		c.Close()
+		return err
+	}
+
+	if p.name == "" {
+		p.name = c.Name
+	}
+	p.c = c
+	return nil
+}
+
+// setup prepares a newly started engine for its first game.
+func (p *player) setup(ctx context.Context, c *client.Client) error {
+	if err := c.Handshake(ctx); err != nil {
+		return err
+	}
+
+	for _, o := range p.options {
+		if err := c.SetOption(o.name, o.value); err != nil {
+			return err
+		}
+	}
+
+	return c.NewGame(ctx)
+}
+
+// stop shuts down the engine, if it's running.
+func (p *player) stop() {
+	if p.c != nil {
+		p.c.Close()
+		p.c = nil
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clfs/aloe/chess"
+	"github.com/clfs/aloe/uci"
+)
+
+// timeControl limits the searches in a game. Any combination of a depth, a
+// node count and a clock may be used.
+type timeControl struct {
+	depth int // If > 0, search this many plies only.
+	nodes int // If > 0, search this many nodes only.
+
+	moves int           // If > 0, the clock is reset to base every this many moves.
+	base  time.Duration // If > 0, each player's initial time.
+	inc   time.Duration // Time added to a player's clock after each move.
+
+	margin time.Duration // How far a player may overrun the clock without losing.
+}
+
+// parseClock parses a clock time control of the form [moves/]base[+inc], with
+// times in seconds, like "40/60" or "10+0.1".
+func parseClock(s string) (moves int, base, inc time.Duration, err error) {
+	rest := s
+
+	if before, after, ok := strings.Cut(rest, "/"); ok {
+		moves, err = strconv.Atoi(before)
+		if err != nil || moves <= 0 {
+			return 0, 0, 0, fmt.Errorf("invalid time control %q: bad move count", s)
+		}
+		rest = after
+	}
+
+	before, after, ok := strings.Cut(rest, "+")
+	if base, err = parseSeconds(before); err != nil || base <= 0 {
+		return 0, 0, 0, fmt.Errorf("invalid time control %q: bad base time", s)
+	}
+	if ok {
+		if inc, err = parseSeconds(after); err != nil || inc < 0 {
+			return 0, 0, 0, fmt.Errorf("invalid time control %q: bad increment", s)
+		}
+	}
+
+	return moves, base, inc, nil
+}
+
+// parseSeconds parses a decimal number of seconds.
+func parseSeconds(s string) (time.Duration, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(f * float64(time.Second)), nil
+}
+
+// hasClock reports whether the time control uses a clock.
+func (tc timeControl) hasClock() bool {
+	return tc.base > 0
+}
+
+// String returns the time control in the format of the PGN TimeControl tag,
+// or "-" if it doesn't use a clock.
+func (tc timeControl) String() string {
+	if !tc.hasClock() {
+		return "-"
+	}
+
+	s := formatSeconds(tc.base)
+	if tc.moves > 0 {
+		s = strconv.Itoa(tc.moves) + "/" + s
+	}
+	if tc.inc > 0 {
+		s += "+" + formatSeconds(tc.inc)
+	}
+	return s
+}
+
+// formatSeconds formats a duration as a decimal number of seconds.
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
+
+// goRequest returns the request for a search by side, given both players'
+// clocks. The clocks are ignored if the time control doesn't use one.
+func (tc timeControl) goRequest(side chess.Color, white, black *clock) *uci.Go {
+	g := &uci.Go{Depth: tc.depth, Nodes: tc.nodes}
+
+	if tc.hasClock() {
+		g.WhiteTime = millis(white.left)
+		g.BlackTime = millis(black.left)
+		g.WhiteIncrement = int(tc.inc.Milliseconds())
+		g.BlackIncrement = int(tc.inc.Milliseconds())
+		if side == chess.White {
+			g.MovesToGo = white.movesToGo()
+		} else {
+			g.MovesToGo = black.movesToGo()
+		}
+	}
+
+	return g
+}
+
+// millis converts a remaining time to milliseconds for the engine. It's at
+// least 1, since 0 means there is no limit.
+func millis(d time.Duration) int {
+	ms := int(d.Milliseconds())
+	if ms < 1 {
+		return 1
+	}
+	return ms
+}
+
+// clock is a player's clock.
+type clock struct {
+	tc    timeControl
+	left  time.Duration
+	moves int // Moves played in the current period.
+}
+
+// newClock returns a clock with the initial time of tc.
+func newClock(tc timeControl) *clock {
+	return &clock{tc: tc, left: tc.base}
+}
+
+// movesToGo returns the number of moves until the clock is reset, or 0 if it
+// never is.
+func (c *clock) movesToGo() int {
+	if c.tc.moves == 0 {
+		return 0
+	}
+	return c.tc.moves - c.moves
+}
+
+// deadline returns how long the player may take for the next move.
+func (c *clock) deadline() time.Duration {
+	return c.left + c.tc.margin
+}
+
+// punch charges a move that took d. It reports false if the player ran out of
+// time.
+func (c *clock) punch(d time.Duration) bool {
+	c.left -= d
+	if c.left < -c.tc.margin {
+		return false
+	}
+	if c.left < 0 {
+		c.left = 0
+	}
+
+	c.left += c.tc.inc
+
+	if c.tc.moves > 0 {
+		c.moves++
+		if c.moves == c.tc.moves {
+			c.left += c.tc.base
+			c.moves = 0
+		}
+	}
+
+	return true
+}
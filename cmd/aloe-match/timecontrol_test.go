@@ -0,0 +1,92 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/clfs/aloe/chess"
+)
+
+func TestParseClock(t *testing.T) {
+	cases := []struct {
+		in    string
+		moves int
+		base  time.Duration
+		inc   time.Duration
+		tag   string // The PGN TimeControl tag.
+	}{
+		{"60", 0, time.Minute, 0, "60"},
+		{"10+0.1", 0, 10 * time.Second, 100 * time.Millisecond, "10+0.1"},
+		{"40/60", 40, time.Minute, 0, "40/60"},
+		{"40/0.5+0", 40, 500 * time.Millisecond, 0, "40/0.5"},
+	}
+
+	for _, tc := range cases {
+		moves, base, inc, err := parseClock(tc.in)
+		if err != nil {
+			t.Errorf("%q: error: %v", tc.in, err)
+			continue
+		}
+		if moves != tc.moves || base != tc.base || inc != tc.inc {
+			t.Errorf("%q: got %d %v %v, want %d %v %v", tc.in, moves, base, inc, tc.moves, tc.base, tc.inc)
+		}
+
+		if got := (timeControl{moves: moves, base: base, inc: inc}).String(); got != tc.tag {
+			t.Errorf("%q: got tag %q, want %q", tc.in, got, tc.tag)
+		}
+	}
+}
+
+func TestParseClock_Invalid(t *testing.T) {
+	for _, in := range []string{"", "0", "-1", "x", "40/", "/60", "0/60", "60+", "60+-1", "60+x"} {
+		if _, _, _, err := parseClock(in); err == nil {
+			t.Errorf("%q: no error", in)
+		}
+	}
+}
+
+func TestClock(t *testing.T) {
+	tc := timeControl{moves: 2, base: time.Second, inc: 100 * time.Millisecond, margin: 50 * time.Millisecond}
+	c := newClock(tc)
+
+	if got := c.movesToGo(); got != 2 {
+		t.Errorf("got %d moves to go, want 2", got)
+	}
+	if !c.punch(400 * time.Millisecond) {
+		t.Fatal("flag fell on move 1")
+	}
+	if got, want := c.left, 700*time.Millisecond; got != want {
+		t.Errorf("after move 1: got %v left, want %v", got, want)
+	}
+	if got := c.movesToGo(); got != 1 {
+		t.Errorf("got %d moves to go, want 1", got)
+	}
+
+	// Overrunning by less than the margin is allowed, and the period resets.
+	if !c.punch(730 * time.Millisecond) {
+		t.Fatal("flag fell on move 2")
+	}
+	if got, want := c.left, 1100*time.Millisecond; got != want {
+		t.Errorf("after move 2: got %v left, want %v", got, want)
+	}
+
+	if c.punch(c.deadline() + time.Millisecond) {
+		t.Error("flag didn't fall")
+	}
+}
+
+func TestTimeControl_GoRequest(t *testing.T) {
+	tc := timeControl{depth: 8, moves: 40, base: time.Minute, inc: time.Second}
+	white, black := newClock(tc), newClock(tc)
+	white.punch(10 * time.Second)
+
+	g := tc.goRequest(chess.Black, white, black)
+	if g.Depth != 8 || g.WhiteTime != 51000 || g.BlackTime != 60000 ||
+		g.WhiteIncrement != 1000 || g.BlackIncrement != 1000 || g.MovesToGo != 40 {
+		t.Errorf("got %+v", g)
+	}
+
+	if g := (timeControl{nodes: 1000}).goRequest(chess.White, nil, nil); g.Nodes != 1000 || g.WhiteTime != 0 {
+		t.Errorf("got %+v", g)
+	}
+}
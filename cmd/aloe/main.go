@@ -3,10 +3,10 @@ package main
 import (
 	"bufio"
 	"errors"
-	"fmt"
 	"log"
 	"os"
 
+	ucienc "github.com/clfs/aloe/encoding/uci"
 	"github.com/clfs/aloe/engine"
 	"github.com/clfs/aloe/uci"
 )
@@ -14,7 +14,12 @@ import (
 func main() {
 	eng := engine.New()
 
+	// Closed when every response has been written.
+	written := make(chan struct{})
+
 	go func() {
+		defer close(written)
+		enc := ucienc.NewEncoder(os.Stdout)
 		for {
 			resp, err := eng.Respond()
 			if errors.Is(err, uci.ErrEngineClosed) {
@@ -23,12 +28,9 @@ func main() {
 				log.Fatal(err)
 			}
 
-			text, err := resp.MarshalText()
-			if err != nil {
+			if err := enc.Encode(resp); err != nil {
 				log.Fatal(err)
 			}
-
-			fmt.Printf("%s\n", text)
 		}
 	}()
 
@@ -37,19 +39,25 @@ func main() {
 	for scanner.Scan() {
 		line := scanner.Text()
 
-		req, err := uci.Parse(line)
+		// GUIs are expected to tolerate bad input, so errors are logged
+		// and otherwise ignored.
+		req, err := uci.ParseRequest(line)
 		if err != nil {
-			log.Fatal(err)
+			log.Print(err)
+			continue
 		}
 
 		err = eng.Do(req)
 		if errors.Is(err, uci.ErrEngineClosed) {
-			return
+			break
 		} else if err != nil {
-			log.Fatal(err)
+			log.Print(err)
 		}
 	}
 
+	eng.Close()
+	<-written
+
 	if err := scanner.Err(); err != nil {
 		log.Fatal(err)
 	}
@@ -3,13 +3,16 @@ package uci
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
+
+	"github.com/clfs/aloe/uci"
 )
 
 // Unmarshal parses the UCI-encoded data and stores the result in the value
 // pointed to by m.
-func Unmarshal(text []byte, m Message) error {
+func Unmarshal(text []byte, m uci.Message) error {
 	return m.UnmarshalText(text)
 }
 
@@ -25,27 +28,81 @@ func NewDecoder(r io.Reader) *Decoder {
 
 // Decode reads the next message from its input and stores it in the value
 // pointed to by m.
-func (d *Decoder) Decode(m Message) error {
-	text, err := d.r.ReadBytes('\n')
+func (d *Decoder) Decode(m uci.Message) error {
+	text, err := d.readLine()
 	if err != nil {
 		return err
 	}
-	return m.UnmarshalText(text[:len(text)-1])
+	return m.UnmarshalText(text)
 }
 
-// Message returns the next UCI message in the input stream. At the end of the
-// input stream, Message returns nil, io.EOF.
-func (d *Decoder) Message() (Message, error) {
-	text, err := d.r.ReadBytes('\n')
+// Message returns the next UCI message in the input stream, as parsed by
+// [uci.Parse]. At the end of the input stream, Message returns nil, io.EOF.
+//
+// If a line can't be parsed, Message returns a [*SyntaxError] and the decoder
+// moves on to the next line. Other errors come from reading the input.
+func (d *Decoder) Message() (uci.Message, error) {
+	text, err := d.readLine()
 	if err != nil {
 		return nil, err
 	}
+	m, err := uci.Parse(string(text))
+	if err != nil {
+		return nil, &SyntaxError{Line: string(text), Err: err}
+	}
+	return m, nil
+}
 
-	fields := bytes.Fields(text)
+// Request is like [Decoder.Message], but returns an error if the message is
+// not a request. Engines use it to read from the GUI.
+func (d *Decoder) Request() (uci.Request, error) {
+	text, err := d.readLine()
+	if err != nil {
+		return nil, err
+	}
+	req, err := uci.ParseRequest(string(text))
+	if err != nil {
+		return nil, &SyntaxError{Line: string(text), Err: err}
+	}
+	return req, nil
+}
 
-	if len(fields) == 0 {
-		return nil, fmt.Errorf("blank message")
+// Response is like [Decoder.Message], but returns an error if the message is
+// not a response. GUIs use it to read from the engine.
+func (d *Decoder) Response() (uci.Response, error) {
+	text, err := d.readLine()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := uci.ParseResponse(string(text))
+	if err != nil {
+		return nil, &SyntaxError{Line: string(text), Err: err}
 	}
+	return resp, nil
+}
+
+// readLine returns the next line of input without its line ending. The last
+// line doesn't need one.
+func (d *Decoder) readLine() ([]byte, error) {
+	text, err := d.r.ReadBytes('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && len(text) > 0) {
+		return nil, err
+	}
+	text = bytes.TrimSuffix(text, []byte("\n"))
+	text = bytes.TrimSuffix(text, []byte("\r"))
+	return text, nil
+}
+
+// A SyntaxError is returned when a line of input can't be parsed.
+type SyntaxError struct {
+	Line string // The line, without its line ending.
+	Err  error  // Why the line couldn't be parsed.
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%q: %v", e.Line, e.Err)
+}
 
-	return nil, io.EOF
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
 }
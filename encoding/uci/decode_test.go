@@ -1 +1,114 @@
 package uci
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/clfs/aloe/chess"
+	"github.com/clfs/aloe/uci"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// cmpOpts compares decoded messages, whose positions can only be compared
+// with ==.
+var cmpOpts = []cmp.Option{
+	cmpopts.EquateEmpty(),
+	cmp.Comparer(func(a, b chess.Position) bool { return a == b }),
+}
+
+func TestDecoder_Message(t *testing.T) {
+	input := strings.Join([]string{
+		"uci",
+		"joho debug on",
+		"  isready  ",
+		"position startpos moves e2e4\r",
+		"info depth 2 score cp 15 pv e7e5 string hello  world",
+		"bestmove e7e5",
+	}, "\n")
+
+	want := []uci.Message{
+		&uci.UCI{},
+		&uci.Debug{Flag: true},
+		&uci.IsReady{},
+		&uci.Position{Start: chess.NewPosition(), Moves: []chess.Move{{From: chess.E2, To: chess.E4}}},
+		&uci.Info{Depth: 2, Score: 15, ScoreType: uci.ScoreTypeCentipawn, PV: []chess.Move{{From: chess.E7, To: chess.E5}}, String: "hello  world"},
+		&uci.BestMove{Move: chess.Move{From: chess.E7, To: chess.E5}},
+	}
+
+	dec := NewDecoder(strings.NewReader(input))
+
+	for _, w := range want {
+		got, err := dec.Message()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(w, got, cmpOpts...); diff != "" {
+			t.Errorf("(-want, +got)\n%s", diff)
+		}
+	}
+
+	if m, err := dec.Message(); !errors.Is(err, io.EOF) {
+		t.Errorf("want io.EOF, got %v, %v", m, err)
+	}
+}
+
+func TestDecoder_Message_Errors(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("joho hello\n\ndebug maybe\nisready\n"))
+
+	var unknown *uci.UnknownCommandError
+	if _, err := dec.Message(); !errors.As(err, &unknown) || unknown.Command != "joho" {
+		t.Errorf("want unknown command joho, got %v", err)
+	}
+
+	var syntax *SyntaxError
+	if _, err := dec.Message(); !errors.As(err, &syntax) || syntax.Line != "" {
+		t.Errorf("want syntax error for a blank line, got %v", err)
+	}
+
+	if _, err := dec.Message(); err == nil || errors.As(err, &unknown) {
+		t.Errorf("want invalid debug error, got %v", err)
+	}
+
+	// Errors don't stop the decoder.
+	if m, err := dec.Message(); err != nil {
+		t.Error(err)
+	} else if _, ok := m.(*uci.IsReady); !ok {
+		t.Errorf("want isready, got %#v", m)
+	}
+}
+
+func TestDecoder_Direction(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("isready\nisready\nreadyok\nreadyok\n"))
+
+	if _, err := dec.Request(); err != nil {
+		t.Error(err)
+	}
+	if _, err := dec.Response(); err == nil {
+		t.Error("want error for a request read as a response")
+	}
+	if _, err := dec.Request(); err == nil {
+		t.Error("want error for a response read as a request")
+	}
+	if _, err := dec.Response(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDecoder_Decode(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("bestmove e2e4 ponder e7e5"))
+
+	var got uci.BestMove
+	if err := dec.Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if want := (uci.BestMove{Move: chess.Move{From: chess.E2, To: chess.E4}, Ponder: chess.Move{From: chess.E7, To: chess.E5}}); got != want {
+		t.Errorf("want %+v, got %+v", want, got)
+	}
+
+	if err := dec.Decode(&got); !errors.Is(err, io.EOF) {
+		t.Errorf("want io.EOF, got %v", err)
+	}
+}
@@ -1,3 +1,4 @@
-// Package uci implements encoding and decoding for Universal Chess Interface
-// (UCI) messages.
+// Package uci implements streaming of Universal Chess Interface (UCI) messages,
+// one per line. The messages themselves are defined in package
+// [github.com/clfs/aloe/uci].
 package uci
@@ -3,10 +3,12 @@ package uci
 import (
 	"fmt"
 	"io"
+
+	"github.com/clfs/aloe/uci"
 )
 
 // Marshal returns the UCI encoding of m.
-func Marshal(m Message) ([]byte, error) {
+func Marshal(m uci.Message) ([]byte, error) {
 	return m.MarshalText()
 }
 
@@ -21,7 +23,7 @@ func NewEncoder(w io.Writer) *Encoder {
 }
 
 // Encode writes m to the stream, followed by a newline character.
-func (e *Encoder) Encode(m Message) error {
+func (e *Encoder) Encode(m uci.Message) error {
 	text, err := m.MarshalText()
 	if err != nil {
 		return err
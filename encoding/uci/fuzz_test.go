@@ -2,18 +2,52 @@ package uci
 
 import (
 	"bytes"
+	"errors"
+	"io"
+	"reflect"
 	"testing"
+
+	"github.com/clfs/aloe/uci"
+	"github.com/google/go-cmp/cmp"
 )
 
 func FuzzDecoderMessage(f *testing.F) {
+	seeds := []string{
+		"uci\nid name Aloe\nid author the Aloe authors\noption name Hash type spin default 16 min 1 max 65536\nuciok\n",
+		"joho debug on\nisready\nreadyok\n",
+		"setoption name Clear Hash\nsetoption name Book File value my book.bin\n",
+		"option name Style type combo default Solid var Solid var Risky\n",
+		"position fen 8/8/8/8/8/8/8/K6k w - - 0 1 moves a1a2\ngo searchmoves a2a3 wtime 100 btime 100 infinite\n",
+		"info depth 2 seldepth 4 multipv 1 score cp -3 lowerbound nodes 10 currline 1 e2e4 pv e2e4 string hi\n",
+		"bestmove e2e4 ponder e7e5\nregister name Stefan MK code 1\nregistration checking\ncopyprotection ok\n",
+	}
+	for _, s := range seeds {
+		f.Add([]byte(s))
+	}
+
 	f.Fuzz(func(t *testing.T, b []byte) {
 		dec := NewDecoder(bytes.NewReader(b))
 		for {
 			msg, err := dec.Message()
-			if err != nil {
+			if errors.Is(err, io.EOF) {
 				break
 			}
-			_ = msg
+			if err != nil {
+				continue
+			}
+
+			// Every decoded message must encode, and decode back to itself.
+			text, err := msg.MarshalText()
+			if err != nil {
+				t.Fatalf("%#v: marshal: %v", msg, err)
+			}
+			got := reflect.New(reflect.TypeOf(msg).Elem()).Interface().(uci.Message)
+			if err := got.UnmarshalText(text); err != nil {
+				t.Fatalf("%q: unmarshal: %v", text, err)
+			}
+			if diff := cmp.Diff(msg, got, cmpOpts...); diff != "" {
+				t.Fatalf("%q: (-decoded, +round trip)\n%s", text, diff)
+			}
 		}
 	})
 }
@@ -3,24 +3,310 @@ package engine
 
 import (
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/clfs/aloe/chess"
+	"github.com/clfs/aloe/nnue"
 	"github.com/clfs/aloe/uci"
 )
 
-type Engine struct{}
+// Engine identification, sent in response to the "uci" command.
+const (
+	Name   = "Aloe"
+	Author = "the Aloe authors"
+)
+
+// Engine is a UCI chess engine. It implements [uci.Engine].
+//
+// Do must not be called concurrently with itself, but Respond and Close may be
+// called concurrently with Do and with each other.
+type Engine struct {
+	responses chan uci.Response
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	pos     chess.Position
+	history []uint64 // Hashes of the positions before pos, oldest first.
+
+	tt           *transpositionTable
+	net          *nnue.Network // Nil to use the classical evaluation.
+	threads      int
+	multiPV      int
+	moveOverhead time.Duration
+
+	// The current search, if any. All three are nil when idle.
+	stop      chan struct{} // Closed to stop the search.
+	ponderhit chan struct{} // Closed when the opponent plays the pondered move.
+	done      chan struct{} // Closed by the search when it exits.
+}
 
+// New returns a new engine, set up at the standard starting position.
 func New() *Engine {
-	return &Engine{}
+	return &Engine{
+		responses: make(chan uci.Response, 64),
+		closed:    make(chan struct{}),
+		pos:       chess.NewPosition(),
+
+		tt:           newTranspositionTable(defaultHashSize),
+		threads:      defaultThreads,
+		multiPV:      defaultMultiPV,
+		moveOverhead: ms(defaultMoveOverhead),
+	}
 }
 
+// Do handles a request. Searches run in the background, so a "go" request
+// returns as soon as the search starts.
 func (e *Engine) Do(req uci.Request) error {
-	return nil // TODO: implement
+	select {
+	case <-e.closed:
+		return uci.ErrEngineClosed
+	default:
+	}
+
+	switch req := req.(type) {
+	case *uci.UCI:
+		e.send(&uci.ID{Name: Name})
+		e.send(&uci.ID{Author: Author})
+		for _, opt := range options {
+			opt := opt
+			e.send(&opt)
+		}
+		e.send(&uci.UCIOk{})
+
+	case *uci.Debug:
+		// Debug mode is not supported, so there is nothing to do.
+
+	case *uci.IsReady:
+		e.send(&uci.ReadyOk{})
+
+	case *uci.UCINewGame:
+		e.stopSearch()
+		e.pos, e.history = chess.NewPosition(), nil
+		e.tt.clear()
+
+	case *uci.Position:
+		pos, history, err := newPosition(req)
+		if err != nil {
+			return err
+		}
+		e.pos, e.history = pos, history
+
+	case *uci.Go:
+		e.stopSearch()
+		e.startSearch(req)
+
+	case *uci.Stop:
+		e.stopSearch()
+
+	case *uci.PonderHit:
+		if e.ponderhit != nil {
+			close(e.ponderhit)
+			e.ponderhit = nil
+		}
+
+	case *uci.SetOption:
+		e.stopSearch()
+		return e.setOption(req)
+
+	case *uci.Quit:
+		e.stopSearch()
+		e.Close()
+		return uci.ErrEngineClosed
+
+	default:
+		return fmt.Errorf("unsupported request: %T", req)
+	}
+
+	return nil
 }
 
+// Respond returns and consumes the oldest response from the engine. Responses
+// queued before the engine was closed are still returned.
 func (e *Engine) Respond() (uci.Response, error) {
-	return nil, fmt.Errorf("not implemented") // TODO: implement
+	select {
+	case resp := <-e.responses:
+		return resp, nil
+	case <-e.closed:
+		select {
+		case resp := <-e.responses:
+			return resp, nil
+		default:
+			return nil, uci.ErrEngineClosed
+		}
+	}
 }
 
+// Close closes the engine. Any running search is abandoned.
 func (e *Engine) Close() error {
-	return nil // TODO: implement
+	e.closeOnce.Do(func() { close(e.closed) })
+	return nil
+}
+
+// send queues a response. It blocks until the response is queued or the
+// engine is closed.
+func (e *Engine) send(resp uci.Response) {
+	select {
+	case e.responses <- resp:
+	case <-e.closed:
+	}
+}
+
+// Default option values.
+const (
+	defaultThreads      = 1
+	defaultMultiPV      = 1
+	defaultMoveOverhead = 10 // In milliseconds.
+)
+
+// Options supported by the engine.
+var (
+	optionHash = uci.Option{
+		Name:    "Hash",
+		Type:    uci.OptionTypeSpin,
+		Default: strconv.Itoa(defaultHashSize),
+		Min:     1,
+		Max:     maxHashSize,
+	}
+	optionClearHash = uci.Option{
+		Name: "Clear Hash",
+		Type: uci.OptionTypeButton,
+	}
+	optionThreads = uci.Option{
+		Name:    "Threads",
+		Type:    uci.OptionTypeSpin,
+		Default: strconv.Itoa(defaultThreads),
+		Min:     1,
+		Max:     256,
+	}
+	optionMultiPV = uci.Option{
+		Name:    "MultiPV",
+		Type:    uci.OptionTypeSpin,
+		Default: strconv.Itoa(defaultMultiPV),
+		Min:     1,
+		Max:     256,
+	}
+	optionPonder = uci.Option{
+		Name:    "Ponder",
+		Type:    uci.OptionTypeCheck,
+		Default: "false",
+	}
+	optionEvalFile = uci.Option{
+		Name: "EvalFile",
+		Type: uci.OptionTypeString,
+	}
+	optionMoveOverhead = uci.Option{
+		Name:    "Move Overhead",
+		Type:    uci.OptionTypeSpin,
+		Default: strconv.Itoa(defaultMoveOverhead),
+		Min:     0,
+		Max:     5000,
+	}
+)
+
+// options lists the options sent in response to the "uci" command.
+var options = []uci.Option{
+	optionHash,
+	optionClearHash,
+	optionThreads,
+	optionMultiPV,
+	optionPonder,
+	optionEvalFile,
+	optionMoveOverhead,
+}
+
+// setOption handles a "setoption" request. Option names are case-insensitive.
+func (e *Engine) setOption(req *uci.SetOption) error {
+	switch {
+	case strings.EqualFold(req.Name, optionHash.Name):
+		n, err := parseSpin(optionHash, req.Value)
+		if err != nil {
+			return err
+		}
+		e.tt.resize(n)
+	case strings.EqualFold(req.Name, optionClearHash.Name):
+		e.tt.clear()
+	case strings.EqualFold(req.Name, optionThreads.Name):
+		n, err := parseSpin(optionThreads, req.Value)
+		if err != nil {
+			return err
+		}
+		e.threads = n
+	case strings.EqualFold(req.Name, optionMultiPV.Name):
+		n, err := parseSpin(optionMultiPV, req.Value)
+		if err != nil {
+			return err
+		}
+		e.multiPV = n
+	case strings.EqualFold(req.Name, optionPonder.Name):
+		// GUIs send "go ponder" only if pondering is enabled, so the value
+		// itself needs no handling.
+		if req.Value != "true" && req.Value != "false" {
+			return fmt.Errorf("invalid value for %s: %q", optionPonder.Name, req.Value)
+		}
+	case strings.EqualFold(req.Name, optionEvalFile.Name):
+		net, err := loadNetwork(req.Value)
+		if err != nil {
+			return err
+		}
+		e.net = net
+	case strings.EqualFold(req.Name, optionMoveOverhead.Name):
+		n, err := parseSpin(optionMoveOverhead, req.Value)
+		if err != nil {
+			return err
+		}
+		e.moveOverhead = ms(n)
+	default:
+		return fmt.Errorf("unknown option: %s", req.Name)
+	}
+	return nil
+}
+
+// loadNetwork loads the network file named by the value of the EvalFile
+// option. An empty value selects the classical evaluation.
+func loadNetwork(value string) (*nnue.Network, error) {
+	if value == "" || value == "<empty>" {
+		return nil, nil
+	}
+
+	f, err := os.Open(value)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return nnue.Load(f)
+}
+
+// parseSpin parses the value of a spin option.
+func parseSpin(opt uci.Option, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < opt.Min || n > opt.Max {
+		return 0, fmt.Errorf("invalid value for %s: %q", opt.Name, value)
+	}
+	return n, nil
+}
+
+// newPosition returns the position described by a "position" request, along
+// with the hashes of the positions before it.
+func newPosition(req *uci.Position) (chess.Position, []uint64, error) {
+	pos := req.Start
+	if err := pos.IsValid(); err != nil {
+		return chess.Position{}, nil, err
+	}
+
+	var history []uint64
+
+	for _, m := range req.Moves {
+		if !pos.IsLegalMove(m) {
+			return chess.Position{}, nil, fmt.Errorf("illegal move: %s", m.LAN())
+		}
+		history = append(history, pos.Hash())
+		pos.Move(m)
+	}
+
+	return pos, history, nil
 }
@@ -0,0 +1,341 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clfs/aloe/nnue"
+	"github.com/clfs/aloe/uci"
+)
+
+// do parses and sends each line to e, failing the test on error.
+func do(t *testing.T, e *Engine, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		req, err := uci.ParseRequest(line)
+		if err != nil {
+			t.Fatalf("%q: parse error: %v", line, err)
+		}
+		if err := e.Do(req); err != nil {
+			t.Fatalf("%q: error: %v", line, err)
+		}
+	}
+}
+
+// respond returns the text of the next response from e.
+func respond(t *testing.T, e *Engine) string {
+	t.Helper()
+	resp, err := e.Respond()
+	if err != nil {
+		t.Fatalf("respond: %v", err)
+	}
+	text, err := resp.MarshalText()
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return string(text)
+}
+
+// respondUntilBestMove returns the text of the responses from e up to and including
+// the first "bestmove".
+func respondUntilBestMove(t *testing.T, e *Engine) []string {
+	t.Helper()
+	var got []string
+	for {
+		text := respond(t, e)
+		got = append(got, text)
+		if strings.HasPrefix(text, "bestmove") {
+			return got
+		}
+	}
+}
+
+func TestEngine_Handshake(t *testing.T) {
+	e := New()
+	defer e.Close()
+
+	do(t, e, "uci")
+
+	for _, want := range []string{"id name " + Name, "id author " + Author} {
+		if got := respond(t, e); got != want {
+			t.Errorf("want %q, got %q", want, got)
+		}
+	}
+
+	for _, opt := range options {
+		want, _ := opt.MarshalText()
+		if got := respond(t, e); got != string(want) {
+			t.Errorf("want %q, got %q", want, got)
+		}
+	}
+
+	if got := respond(t, e); got != "uciok" {
+		t.Errorf("want uciok, got %q", got)
+	}
+
+	do(t, e, "isready")
+
+	if got := respond(t, e); got != "readyok" {
+		t.Errorf("want readyok, got %q", got)
+	}
+}
+
+func TestEngine_Go(t *testing.T) {
+	e := New()
+	defer e.Close()
+
+	do(t, e, "ucinewgame", "position startpos moves e2e4 e7e5", "go depth 1")
+
+	got := respondUntilBestMove(t, e)
+	if len(got) < 2 {
+		t.Fatalf("want info before bestmove, got %q", got)
+	}
+	for _, text := range got[:len(got)-1] {
+		if !strings.HasPrefix(text, "info") {
+			t.Errorf("want info, got %q", text)
+		}
+	}
+}
+
+func TestEngine_GoSearchMoves(t *testing.T) {
+	e := New()
+	defer e.Close()
+
+	do(t, e, "position startpos", "go depth 1 searchmoves a2a3")
+
+	got := respondUntilBestMove(t, e)
+	if want := "bestmove a2a3"; got[len(got)-1] != want {
+		t.Errorf("want %q, got %q", want, got[len(got)-1])
+	}
+}
+
+func TestEngine_GoInfinite(t *testing.T) {
+	e := New()
+	defer e.Close()
+
+	do(t, e, "position startpos", "go infinite", "stop")
+
+	// Stop waits for the search to exit, so bestmove is already queued.
+	respondUntilBestMove(t, e)
+}
+
+func TestEngine_NoLegalMoves(t *testing.T) {
+	e := New()
+	defer e.Close()
+
+	do(t, e, "position startpos moves f2f3 e7e5 g2g4 d8h4", "go depth 1")
+
+	got := respondUntilBestMove(t, e)
+	if want := "bestmove 0000"; got[len(got)-1] != want {
+		t.Errorf("want %q, got %q", want, got[len(got)-1])
+	}
+}
+
+func TestEngine_InvalidRequests(t *testing.T) {
+	e := New()
+	defer e.Close()
+
+	for _, line := range []string{
+		"position startpos moves e2e5",
+		"setoption name NoSuchOption value 1",
+		"setoption name Move Overhead value -1",
+		"setoption name Move Overhead value lots",
+		"setoption name Hash value 0",
+	} {
+		req, err := uci.ParseRequest(line)
+		if err != nil {
+			t.Fatalf("%q: parse error: %v", line, err)
+		}
+		if err := e.Do(req); err == nil {
+			t.Errorf("%q: expected error, got nil", line)
+		}
+	}
+
+	// Invalid positions don't parse, but may still be built directly.
+	if err := e.Do(&uci.Position{}); err == nil {
+		t.Errorf("empty position: expected error, got nil")
+	}
+}
+
+func TestEngine_SetOption(t *testing.T) {
+	e := New()
+	defer e.Close()
+
+	do(t, e, "setoption name move overhead value 250")
+
+	if want := 250 * time.Millisecond; e.moveOverhead != want {
+		t.Errorf("want move overhead %v, got %v", want, e.moveOverhead)
+	}
+
+	do(t, e, "setoption name Hash value 2", "setoption name Clear Hash")
+
+	if want := 2 << 20 / ttEntrySize; len(e.tt.entries) != want {
+		t.Errorf("want %d hash entries, got %d", want, len(e.tt.entries))
+	}
+}
+
+func TestEngine_EvalFile(t *testing.T) {
+	e := New()
+	defer e.Close()
+
+	net := &nnue.Network{
+		Hidden:        4,
+		HiddenWeights: make([]int16, nnue.Inputs*4),
+		HiddenBiases:  []int16{10, 20, 30, 40},
+		OutputWeights: []int16{1, 2, 3, 4, -4, -3, -2, -1},
+	}
+	name := filepath.Join(t.TempDir(), "test.nnue")
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := net.Save(f); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	do(t, e, "setoption name EvalFile value "+name)
+	if e.net == nil {
+		t.Fatal("network not loaded")
+	}
+
+	do(t, e, "position startpos", "go depth 2")
+	respondUntilBestMove(t, e)
+
+	do(t, e, "setoption name EvalFile value <empty>")
+	if e.net != nil {
+		t.Error("network not unloaded")
+	}
+
+	req, _ := uci.ParseRequest("setoption name EvalFile value " + filepath.Join(t.TempDir(), "missing.nnue"))
+	if err := e.Do(req); err == nil {
+		t.Error("missing file: expected error, got nil")
+	}
+}
+
+func TestEngine_Threads(t *testing.T) {
+	e := New()
+	defer e.Close()
+
+	do(t, e, "setoption name Threads value 4", "position startpos", "go depth 5")
+
+	got := respondUntilBestMove(t, e)
+	if !strings.Contains(got[len(got)-2], "depth 5") {
+		t.Errorf("want a depth 5 info before bestmove, got %q", got)
+	}
+
+	// Helpers are stopped along with the main thread.
+	do(t, e, "go infinite", "stop")
+	respondUntilBestMove(t, e)
+
+	do(t, e, "go infinite")
+	e.Close()
+	select {
+	case <-e.done:
+	case <-time.After(5 * time.Second):
+		t.Error("search still running after Close")
+	}
+}
+
+func TestEngine_MultiPV(t *testing.T) {
+	e := New()
+	defer e.Close()
+
+	do(t, e, "setoption name MultiPV value 3", "position startpos", "go depth 3")
+
+	got := respondUntilBestMove(t, e)
+	if len(got) != 3*3+1 {
+		t.Fatalf("want 3 info lines per depth, got %q", got)
+	}
+
+	// The last iteration reports three lines with distinct first moves.
+	first := make(map[string]bool)
+	for i, line := range got[6:9] {
+		if !strings.Contains(line, fmt.Sprintf("depth 3 multipv %d ", i+1)) {
+			t.Errorf("want line %d at depth 3, got %q", i+1, line)
+		}
+		if _, pv, ok := strings.Cut(line, " pv "); ok {
+			first[strings.Fields(pv)[0]] = true
+		}
+	}
+	if len(first) != 3 {
+		t.Errorf("want distinct first moves, got %q", got[6:9])
+	}
+
+	// There are fewer lines than requested when there are fewer legal moves.
+	do(t, e, "position fen 7k/8/8/8/8/8/8/K7 w - - 0 1", "setoption name MultiPV value 10", "go depth 1")
+	if got := respondUntilBestMove(t, e); len(got) != 3+1 {
+		t.Errorf("want 3 info lines, got %q", got)
+	}
+}
+
+func TestEngine_Ponder(t *testing.T) {
+	e := New()
+	defer e.Close()
+
+	do(t, e, "setoption name Ponder value true")
+
+	// Ponder on Black's reply to 1. e4, then have it played.
+	do(t, e, "position startpos moves e2e4 e7e5", "go ponder wtime 1000 btime 1000")
+
+	// No bestmove may be sent while pondering.
+	time.Sleep(100 * time.Millisecond)
+	for len(e.responses) > 0 {
+		if text := respond(t, e); strings.HasPrefix(text, "bestmove") {
+			t.Fatalf("bestmove while pondering: %q", text)
+		}
+	}
+
+	start := time.Now()
+	do(t, e, "ponderhit")
+
+	got := respondUntilBestMove(t, e)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("searched for %v after ponderhit with 1s on the clock", elapsed)
+	}
+	if !strings.Contains(got[len(got)-1], " ponder ") {
+		t.Errorf("want a ponder move, got %q", got[len(got)-1])
+	}
+
+	// A stopped ponder search still sends bestmove.
+	do(t, e, "go ponder wtime 1000 btime 1000", "stop")
+	respondUntilBestMove(t, e)
+}
+
+func TestEngine_GoClock(t *testing.T) {
+	e := New()
+	defer e.Close()
+
+	start := time.Now()
+	do(t, e, "position startpos", "go wtime 2000 btime 2000")
+	respondUntilBestMove(t, e)
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("searched for %v with 2s on the clock", elapsed)
+	}
+}
+
+func TestEngine_Quit(t *testing.T) {
+	e := New()
+
+	req, _ := uci.ParseRequest("quit")
+	if err := e.Do(req); !errors.Is(err, uci.ErrEngineClosed) {
+		t.Errorf("quit: want ErrEngineClosed, got %v", err)
+	}
+
+	if _, err := e.Respond(); !errors.Is(err, uci.ErrEngineClosed) {
+		t.Errorf("respond: want ErrEngineClosed, got %v", err)
+	}
+
+	req, _ = uci.ParseRequest("isready")
+	if err := e.Do(req); !errors.Is(err, uci.ErrEngineClosed) {
+		t.Errorf("isready: want ErrEngineClosed, got %v", err)
+	}
+}
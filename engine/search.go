@@ -0,0 +1,781 @@
+package engine
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/clfs/aloe/chess"
+	"github.com/clfs/aloe/eval"
+	"github.com/clfs/aloe/nnue"
+	"github.com/clfs/aloe/uci"
+)
+
+// Search limits and scores.
+const (
+	maxPly     = 128   // Maximum search depth, in plies from the root.
+	mateScore  = 32000 // Score for delivering checkmate at the root.
+	infinity   = 32001 // Bound outside every possible score.
+	mateBound  = mateScore - maxPly
+	checkNodes = 1 << 10 // Nodes between checks of the stop conditions.
+
+	// failLowMargin is how far the score must drop between iterations for
+	// the search to be extended.
+	failLowMargin = 30
+)
+
+// roleValues are rough piece values in centipawns, indexed by [chess.Role].
+// They are used to order captures, so the king's value is irrelevant.
+var roleValues = [...]int{
+	chess.Pawn:   100,
+	chess.Knight: 320,
+	chess.Bishop: 330,
+	chess.Rook:   500,
+	chess.Queen:  900,
+	chess.King:   0,
+}
+
+// startSearch starts a search of the current position in the background.
+func (e *Engine) startSearch(req *uci.Go) {
+	e.stop = make(chan struct{})
+	e.ponderhit = make(chan struct{})
+	e.done = make(chan struct{})
+
+	e.tt.newSearch()
+
+	start := time.Now()
+	shared := &sharedState{start: start}
+	tm := newTimeManager(start, req, e.pos.SideToMove, e.moveOverhead)
+
+	// A ponder search turns into a normal search on ponderhit, with the clock
+	// starting then.
+	normal := *req
+	normal.Ponder = false
+	afterPonder := newTimeManager(start, &normal, e.pos.SideToMove, e.moveOverhead)
+
+	threads := make([]*searcher, e.threads)
+	for i := range threads {
+		s := &searcher{
+			id:      i,
+			pos:     e.pos,
+			history: append([]uint64(nil), e.history...),
+			limits:  *req,
+			shared:  shared,
+			tt:      e.tt,
+			tm:      tm,
+			stop:    e.stop,
+			closed:  e.closed,
+		}
+		if e.net != nil {
+			s.nn = e.net.NewEvaluator(&s.pos)
+		}
+		if i == 0 {
+			s.multiPV = e.multiPV
+		}
+		if i == 0 && req.Ponder {
+			s.pondering, s.ponderhit, s.afterPonder = true, e.ponderhit, afterPonder
+		}
+		threads[i] = s
+	}
+
+	go e.search(threads, e.ponderhit, e.done)
+}
+
+// stopSearch stops the current search, if any, and waits for it to exit.
+func (e *Engine) stopSearch() {
+	if e.stop == nil {
+		return
+	}
+
+	close(e.stop)
+	<-e.done
+
+	e.stop, e.ponderhit, e.done = nil, nil, nil
+}
+
+// search runs a search on the given threads and sends the results. It closes
+// done when every thread has exited.
+//
+// The search is a Lazy SMP search: the threads search the same position
+// independently, sharing only the transposition table, and the results of the
+// first thread are used. The other threads help by filling the table.
+//
+// The UCI protocol forbids sending "bestmove" before "stop" or "ponderhit"
+// when searching in infinite or ponder mode, so search waits for them.
+func (e *Engine) search(threads []*searcher, ponderhit <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	main := threads[0]
+
+	var wg sync.WaitGroup
+	for _, s := range threads[1:] {
+		wg.Add(1)
+		go func(s *searcher) {
+			defer wg.Done()
+			s.iterate(nil)
+		}(s)
+	}
+
+	pv := main.iterate(func(info uci.Info) { e.send(&info) })
+
+	// The helpers stop when the main thread does.
+	main.shared.halt.Store(true)
+	wg.Wait()
+
+	if main.limits.Infinite || main.limits.Ponder {
+		if main.limits.Infinite {
+			ponderhit = nil // An infinite search continues after a ponderhit.
+		}
+		select {
+		case <-main.stop:
+		case <-ponderhit:
+		case <-e.closed:
+			return
+		}
+	}
+
+	// With no legal moves, the best move is the null move.
+	resp := &uci.BestMove{}
+	if len(pv) > 0 {
+		resp.Move = pv[0]
+	}
+	if m, ok := main.ponderMove(pv); ok {
+		resp.Ponder = m
+	}
+
+	e.send(resp)
+}
+
+// ponderMove returns the expected reply to the first move of pv: the second
+// move of pv, or failing that, the best move stored in the transposition
+// table.
+func (s *searcher) ponderMove(pv []chess.Move) (chess.Move, bool) {
+	switch len(pv) {
+	case 0:
+		return chess.Move{}, false
+	case 1:
+		pos := s.pos
+		pos.Move(pv[0])
+		d, ok := s.tt.probe(pos.Hash())
+		if !ok || !pos.IsLegalMove(d.move) {
+			return chess.Move{}, false
+		}
+		return d.move, true
+	default:
+		return pv[1], true
+	}
+}
+
+// sharedState is the state shared by all threads of a search, other than the
+// transposition table.
+type sharedState struct {
+	start time.Time    // When the search started.
+	halt  atomic.Bool  // Set when the main thread has finished.
+	nodes atomic.Int64 // Nodes searched by all threads, updated periodically.
+}
+
+// A searcher searches a single position with alpha-beta pruning. Each search
+// thread has its own searcher.
+type searcher struct {
+	id      int // Thread number. The main thread is 0.
+	pos     chess.Position
+	history []uint64 // Hashes of the positions before pos, oldest first.
+	limits  uci.Go
+
+	shared *sharedState
+	tt     *transpositionTable
+	nn     *nnue.Evaluator // Nil to use the classical evaluation.
+	tm     timeManager
+	stop   <-chan struct{} // Closed to stop the search.
+
+	// While pondering, the search is unlimited until ponderhit is closed,
+	// when the time manager is replaced by afterPonder.
+	pondering   bool
+	ponderhit   <-chan struct{}
+	afterPonder timeManager
+
+	closed <-chan struct{} // Closed when the engine is closed.
+
+	nodes   int // Nodes searched by this thread.
+	flushed int // Nodes already added to shared.nodes.
+	stopped bool
+
+	rootMoves []chess.Move // Moves to search at the root.
+	multiPV   int          // Number of lines to search. Zero means one.
+	pvIndex   int          // Index of the line being searched.
+
+	pv       [maxPly + 1][]chess.Move // Principal variation at each ply.
+	prevPV   []chess.Move             // Principal variation of the previous iteration.
+	followPV bool                     // Whether the current line follows prevPV.
+	killers  [maxPly][2]chess.Move    // Quiet moves that caused a beta cutoff.
+}
+
+// flushNodes adds the nodes searched since the last flush to the shared count.
+func (s *searcher) flushNodes() {
+	s.shared.nodes.Add(int64(s.nodes - s.flushed))
+	s.flushed = s.nodes
+}
+
+// totalNodes returns the number of nodes searched by all threads, as far as
+// this thread knows.
+func (s *searcher) totalNodes() int {
+	return int(s.shared.nodes.Load()) + s.nodes - s.flushed
+}
+
+// iterate runs an iterative deepening search. It returns the principal
+// variation of the deepest completed iteration, which is empty if there are no
+// legal moves.
+//
+// The main thread calls report after every completed iteration and decides
+// when the search is over. Helper threads pass a nil report and search until
+// they are stopped, starting at different depths to vary their work.
+func (s *searcher) iterate(report func(uci.Info)) []chess.Move {
+	s.rootMoves = s.pos.LegalMoves()
+	if len(s.limits.SearchMoves) > 0 {
+		s.rootMoves = filterMoves(s.rootMoves, func(m chess.Move) bool {
+			for _, sm := range s.limits.SearchMoves {
+				if m == sm {
+					return true
+				}
+			}
+			return false
+		})
+	}
+	if len(s.rootMoves) == 0 {
+		return nil
+	}
+
+	maxDepth := maxPly
+	if s.limits.Depth > 0 {
+		maxDepth = s.limits.Depth
+	}
+	if maxDepth > maxPly {
+		maxDepth = maxPly
+	}
+
+	all := s.rootMoves
+	defer func() { s.rootMoves = all }()
+
+	multiPV := s.multiPV
+	if multiPV < 1 {
+		multiPV = 1
+	}
+	if multiPV > len(all) {
+		multiPV = len(all)
+	}
+
+	// If the search is stopped during the first iteration, any legal move is
+	// better than none.
+	best := all[:1]
+
+	// The lines of the deepest completed iteration, best first.
+	var lines []line
+
+	// How unsettled the search is. It grows when the best move changes and
+	// decays with every iteration that keeps it.
+	instability := 0.0
+	prevScore := 0
+
+	for depth := 1 + s.id%2; depth <= maxDepth; depth++ {
+		var current []line
+
+		// Search each line with the first moves of the earlier lines
+		// excluded from the root.
+		for s.pvIndex = 0; s.pvIndex < multiPV; s.pvIndex++ {
+			s.rootMoves = filterMoves(append([]chess.Move(nil), all...), func(m chess.Move) bool {
+				for _, l := range current {
+					if l.pv[0] == m {
+						return false
+					}
+				}
+				return true
+			})
+
+			s.followPV = true
+			s.prevPV = nil
+			if s.pvIndex < len(lines) {
+				s.prevPV = lines[s.pvIndex].pv
+			}
+
+			score := s.negamax(depth, 0, -infinity, infinity)
+			if s.stopped {
+				break
+			}
+
+			current = append(current, line{score, append([]chess.Move(nil), s.pv[0]...)})
+		}
+		if s.stopped {
+			break
+		}
+
+		sort.SliceStable(current, func(i, j int) bool {
+			return current[i].score > current[j].score
+		})
+
+		score := current[0].score
+
+		instability /= 2
+		if depth > 1 && current[0].pv[0] != best[0] {
+			instability++
+		}
+
+		best, lines = current[0].pv, current
+
+		if report == nil {
+			continue
+		}
+
+		s.flushNodes()
+		nodes, elapsed := int(s.shared.nodes.Load()), time.Since(s.shared.start)
+
+		for i, l := range lines {
+			info := uci.Info{
+				Depth:    depth,
+				Nodes:    nodes,
+				Time:     int(elapsed.Milliseconds()),
+				HashFull: s.tt.hashfull(),
+			}
+			if multiPV > 1 {
+				info.MultiPV = i + 1
+			}
+			if elapsed > 0 {
+				info.NPS = int(float64(nodes) / elapsed.Seconds())
+			}
+			info.Score, info.ScoreType = uciScore(l.score)
+			info.PV = l.pv
+			report(info)
+		}
+
+		// Stop once a short enough mate is found. Infinite searches go on
+		// until told to stop.
+		if s.limits.Mate > 0 && !s.limits.Infinite {
+			if n, typ := uciScore(score); typ == uci.ScoreTypeMate && 0 < n && n <= s.limits.Mate {
+				break
+			}
+		}
+
+		// With only one move to play, there is nothing to think about.
+		if s.tm.limited && len(all) == 1 {
+			break
+		}
+
+		// Spend more time when the best move keeps changing or the score
+		// drops, since the last iteration may have missed something.
+		scale := 1 + instability
+		if depth > 1 && score < prevScore-failLowMargin {
+			scale *= 1.5
+		}
+		prevScore = score
+
+		s.checkPonderhit()
+		if s.tm.softExceeded(scale) {
+			break
+		}
+	}
+
+	return best
+}
+
+// A line is a principal variation from the root and its score.
+type line struct {
+	score int
+	pv    []chess.Move
+}
+
+// uciScore converts a search score into a UCI score. Mate scores are given in
+// moves rather than plies; negative if the engine is getting mated.
+func uciScore(score int) (int, string) {
+	switch {
+	case score >= mateBound:
+		return (mateScore - score + 1) / 2, uci.ScoreTypeMate
+	case score <= -mateBound:
+		return -(mateScore + score) / 2, uci.ScoreTypeMate
+	default:
+		return score, uci.ScoreTypeCentipawn
+	}
+}
+
+// shouldStop returns true if the search must stop. Once it returns true, it
+// keeps returning true.
+func (s *searcher) shouldStop() bool {
+	if s.stopped {
+		return true
+	}
+
+	if s.limits.Nodes > 0 && s.totalNodes() >= s.limits.Nodes {
+		s.stopped = true
+		return true
+	}
+
+	if s.nodes%checkNodes != 0 {
+		return false
+	}
+
+	s.flushNodes()
+
+	if s.shared.halt.Load() {
+		s.stopped = true
+		return true
+	}
+
+	s.checkPonderhit()
+
+	select {
+	case <-s.stop:
+		s.stopped = true
+	case <-s.closed:
+		s.stopped = true
+	default:
+		s.stopped = s.tm.hardExceeded()
+	}
+
+	return s.stopped
+}
+
+// checkPonderhit turns a ponder search into a normal search once the
+// opponent has played the expected move.
+func (s *searcher) checkPonderhit() {
+	if !s.pondering {
+		return
+	}
+
+	select {
+	case <-s.ponderhit:
+		s.pondering = false
+		s.tm = s.afterPonder
+		s.tm.start = time.Now()
+	default:
+	}
+}
+
+// negamax returns the score of the current position, searched to the given
+// depth with a principal variation search. Scores outside the (alpha, beta)
+// window are bounds.
+func (s *searcher) negamax(depth, ply, alpha, beta int) int {
+	s.pv[ply] = s.pv[ply][:0]
+
+	if depth <= 0 {
+		return s.quiesce(ply, alpha, beta)
+	}
+
+	s.nodes++
+	if s.shouldStop() {
+		return 0
+	}
+
+	if ply > 0 {
+		if s.isDraw() {
+			return 0
+		}
+
+		// Mate distance pruning: no line from here beats a mate found closer
+		// to the root.
+		if alpha < -mateScore+ply {
+			alpha = -mateScore + ply
+		}
+		if beta > mateScore-ply-1 {
+			beta = mateScore - ply - 1
+		}
+		if alpha >= beta {
+			return alpha
+		}
+	}
+
+	if ply >= maxPly {
+		return s.evaluate()
+	}
+
+	key := s.pos.Hash()
+
+	var ttMove chess.Move
+	if d, ok := s.tt.probe(key); ok {
+		ttMove = d.move
+
+		// Only cut off in null window nodes, so that the principal variation
+		// is searched in full.
+		if ply > 0 && beta-alpha == 1 && d.depth >= depth {
+			score := scoreFromTT(d.score, ply)
+			switch {
+			case d.bound == boundExact,
+				d.bound == boundLower && score >= beta,
+				d.bound == boundUpper && score <= alpha:
+				return score
+			}
+		}
+	}
+
+	inCheck := s.pos.InCheck()
+	if inCheck {
+		depth++
+	}
+
+	moves := s.rootMoves
+	if ply > 0 {
+		moves = s.pos.LegalMoves()
+	}
+	if len(moves) == 0 {
+		if inCheck {
+			return -mateScore + ply
+		}
+		return 0
+	}
+
+	s.orderMoves(moves, ply, ttMove)
+
+	origAlpha := alpha
+	best, bestMove := -infinity, chess.Move{}
+	for i, m := range moves {
+		capture := s.isCapture(m)
+
+		u := s.makeMove(m)
+
+		// Search the first move with a full window and the rest with a null
+		// window, researching only if they turn out to be better.
+		var score int
+		if i == 0 {
+			score = -s.negamax(depth-1, ply+1, -beta, -alpha)
+		} else {
+			score = -s.negamax(depth-1, ply+1, -alpha-1, -alpha)
+			if alpha < score && score < beta {
+				score = -s.negamax(depth-1, ply+1, -beta, -alpha)
+			}
+		}
+
+		s.unmakeMove(u)
+
+		// Only the first move can continue the previous principal variation.
+		s.followPV = false
+
+		if s.stopped {
+			return 0
+		}
+
+		if score > best {
+			best = score
+		}
+
+		if score > alpha {
+			alpha, bestMove = score, m
+			s.pv[ply] = append(append(s.pv[ply][:0], m), s.pv[ply+1]...)
+		}
+
+		if alpha >= beta {
+			if !capture && m.PromotionInfo == chess.NoPromotion && s.killers[ply][0] != m {
+				s.killers[ply][1] = s.killers[ply][0]
+				s.killers[ply][0] = m
+			}
+			break
+		}
+	}
+
+	b := boundExact
+	switch {
+	case best >= beta:
+		b = boundLower
+	case best <= origAlpha:
+		b = boundUpper
+	}
+	// With MultiPV, the root results after the first line are for a subset
+	// of the moves, so they can't be stored.
+	if ply > 0 || s.pvIndex == 0 {
+		s.tt.store(key, ttData{move: bestMove, score: scoreToTT(best, ply), depth: depth, bound: b})
+	}
+
+	return best
+}
+
+// quiesce returns the score of the current position, searching captures and
+// queen promotions only until the position is quiet. Check evasions are
+// searched in full so that mates are not missed.
+func (s *searcher) quiesce(ply, alpha, beta int) int {
+	s.pv[ply] = s.pv[ply][:0]
+
+	s.nodes++
+	if s.shouldStop() {
+		return 0
+	}
+
+	if ply >= maxPly {
+		return s.evaluate()
+	}
+
+	inCheck := s.pos.InCheck()
+
+	moves := s.pos.LegalMoves()
+	if len(moves) == 0 {
+		if inCheck {
+			return -mateScore + ply
+		}
+		return 0
+	}
+
+	best := -infinity
+	if !inCheck {
+		// Standing pat: the side to move is assumed to have a move at least
+		// as good as doing nothing.
+		best = s.evaluate()
+		if best >= beta {
+			return best
+		}
+		if best > alpha {
+			alpha = best
+		}
+
+		moves = filterMoves(moves, func(m chess.Move) bool {
+			return s.isCapture(m) || m.PromotionInfo == chess.QueenPromotion
+		})
+	}
+
+	s.orderMoves(moves, ply, chess.Move{})
+
+	for _, m := range moves {
+		u := s.makeMove(m)
+		score := -s.quiesce(ply+1, -beta, -alpha)
+		s.unmakeMove(u)
+
+		if s.stopped {
+			return 0
+		}
+
+		if score > best {
+			best = score
+		}
+
+		if score > alpha {
+			alpha = score
+			s.pv[ply] = append(append(s.pv[ply][:0], m), s.pv[ply+1]...)
+		}
+
+		if alpha >= beta {
+			break
+		}
+	}
+
+	return best
+}
+
+// evaluate returns a static evaluation of the current position, from the
+// point of view of the side to move. It's clamped to below the mate scores,
+// which a network with large weights could otherwise reach.
+func (s *searcher) evaluate() int {
+	var score int
+	if s.nn != nil {
+		score = s.nn.Evaluate(&s.pos)
+	} else {
+		score = eval.Evaluate(&s.pos)
+	}
+
+	switch {
+	case score >= mateBound:
+		return mateBound - 1
+	case score <= -mateBound:
+		return -mateBound + 1
+	}
+	return score
+}
+
+// makeMove makes a move, records the previous position for repetition
+// detection and updates the network accumulator, if any.
+func (s *searcher) makeMove(m chess.Move) *chess.Undo {
+	s.history = append(s.history, s.pos.Hash())
+	if s.nn != nil {
+		s.nn.Push(&s.pos, m)
+	}
+	return s.pos.Move(m)
+}
+
+// unmakeMove undoes a move made by makeMove.
+func (s *searcher) unmakeMove(u *chess.Undo) {
+	s.pos.Undo(u)
+	if s.nn != nil {
+		s.nn.Pop()
+	}
+	s.history = s.history[:len(s.history)-1]
+}
+
+// isDraw returns true if the current position is drawn by the fifty-move rule,
+// insufficient material or repetition. Inside the search, a single repetition
+// is enough, since the side that could avoid it has no reason to repeat.
+func (s *searcher) isDraw() bool {
+	if s.pos.IsFiftyMoveDraw() || s.pos.HasInsufficientMaterial() {
+		return true
+	}
+
+	return chess.CountRepetitions(s.history, s.pos.Hash(), s.pos.HalfMoveClock) > 0
+}
+
+// isCapture returns true if m captures a piece in the current position.
+func (s *searcher) isCapture(m chess.Move) bool {
+	theirs := s.pos.Board.ByColor(s.pos.SideToMove.Other())
+	if theirs.Get(m.To) {
+		return true
+	}
+	pawns := s.pos.Board.ByRole(chess.Pawn)
+	return s.pos.EnPassantFlag && m.To == s.pos.EnPassantSquare && pawns.Get(m.From)
+}
+
+// orderMoves sorts moves so that the most promising are searched first: the
+// move from the previous principal variation, then the move from the
+// transposition table, then captures by most valuable victim and least
+// valuable attacker, then killer moves.
+func (s *searcher) orderMoves(moves []chess.Move, ply int, ttMove chess.Move) {
+	var pvMove chess.Move
+	if s.followPV {
+		if ply < len(s.prevPV) {
+			pvMove = s.prevPV[ply]
+		} else {
+			s.followPV = false
+		}
+	}
+
+	scores := make([]int, len(moves))
+	for i, m := range moves {
+		scores[i] = s.scoreMove(m, ply, pvMove, ttMove)
+	}
+
+	// Insertion sort, since move lists are short.
+	for i := 1; i < len(moves); i++ {
+		for j := i; j > 0 && scores[j] > scores[j-1]; j-- {
+			scores[j], scores[j-1] = scores[j-1], scores[j]
+			moves[j], moves[j-1] = moves[j-1], moves[j]
+		}
+	}
+}
+
+// scoreMove returns the ordering score of a move. Higher is better.
+func (s *searcher) scoreMove(m chess.Move, ply int, pvMove, ttMove chess.Move) int {
+	switch {
+	case m == pvMove:
+		return 1 << 30
+	case m == ttMove:
+		return 1 << 29
+	case s.isCapture(m):
+		victim, ok := s.pos.Board.At(m.To)
+		if !ok {
+			victim.Role = chess.Pawn // En passant.
+		}
+		attacker, _ := s.pos.Board.At(m.From)
+		return 1<<20 + 16*roleValues[victim.Role] - roleValues[attacker.Role]/16
+	case m.PromotionInfo == chess.QueenPromotion:
+		return 1<<20 + roleValues[chess.Queen]
+	case ply < maxPly && m == s.killers[ply][0]:
+		return 1 << 19
+	case ply < maxPly && m == s.killers[ply][1]:
+		return 1<<19 - 1
+	default:
+		return 0
+	}
+}
+
+// filterMoves returns the moves for which keep returns true. It reuses the
+// backing array of moves.
+func filterMoves(moves []chess.Move, keep func(chess.Move) bool) []chess.Move {
+	kept := moves[:0]
+	for _, m := range moves {
+		if keep(m) {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,152 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/clfs/aloe/chess"
+	"github.com/clfs/aloe/fen"
+	"github.com/clfs/aloe/nnue"
+	"github.com/clfs/aloe/uci"
+)
+
+// newTestSearcher returns a searcher for the position in FEN s.
+func newTestSearcher(t *testing.T, s string, limits uci.Go) *searcher {
+	t.Helper()
+	pos, err := fen.Decode(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &searcher{pos: pos, limits: limits, shared: new(sharedState), tt: newTranspositionTable(1)}
+}
+
+func TestSearcher_Mate(t *testing.T) {
+	cases := []struct {
+		fen      string
+		depth    int
+		wantMove string
+		wantMate int
+	}{
+		// Scholar's mate.
+		{"r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 4 4", 2, "h5f7", 1},
+		// Back rank mate.
+		{"6k1/5ppp/8/8/8/8/5PPP/R5K1 w - - 0 1", 2, "a1a8", 1},
+		// Mate in three, starting with a bishop sacrifice.
+		{"r1b1kb1r/pppp1ppp/5q2/4n3/3KP3/2N3PN/PPP4P/R1BQ1B1R b kq - 0 1", 5, "f8c5", 3},
+		// Black is getting mated.
+		{"k7/8/1K6/8/8/8/8/7R b - - 0 1", 3, "a8b8", -1},
+	}
+
+	for _, c := range cases {
+		s := newTestSearcher(t, c.fen, uci.Go{Depth: c.depth})
+
+		var last uci.Info
+		pv := s.iterate(func(info uci.Info) { last = info })
+
+		if c.wantMove != "" && (len(pv) == 0 || pv[0].LAN() != c.wantMove) {
+			t.Errorf("%s: want %s, got %v", c.fen, c.wantMove, last.PV)
+		}
+		if last.ScoreType != uci.ScoreTypeMate || last.Score != c.wantMate {
+			t.Errorf("%s: want mate %d, got %s %d", c.fen, c.wantMate, last.ScoreType, last.Score)
+		}
+	}
+}
+
+func TestSearcher_InsufficientMaterial(t *testing.T) {
+	// Black is a knight up, but every move leaves a draw.
+	s := newTestSearcher(t, "8/8/8/4k3/8/8/3n4/4K3 w - - 0 1", uci.Go{Depth: 4})
+
+	var last uci.Info
+	s.iterate(func(info uci.Info) { last = info })
+
+	if last.ScoreType != uci.ScoreTypeCentipawn || last.Score != 0 {
+		t.Errorf("want cp 0, got %s %d", last.ScoreType, last.Score)
+	}
+}
+
+func TestSearcher_isDraw(t *testing.T) {
+	cases := []struct {
+		moves []string
+		want  bool
+	}{
+		{nil, false},
+		{[]string{"g1f3", "g8f6"}, false},
+		{[]string{"g1f3", "g8f6", "f3g1", "f6g8"}, true},
+		{[]string{"g1f3", "g8f6", "f3g1", "f6g8", "e2e4"}, false},
+	}
+
+	for _, c := range cases {
+		req := &uci.Position{Start: chess.NewPosition()}
+		for _, lan := range c.moves {
+			m, err := chess.NewMove(lan)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Moves = append(req.Moves, m)
+		}
+		pos, history, err := newPosition(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := &searcher{pos: pos, history: history}
+		if got := s.isDraw(); got != c.want {
+			t.Errorf("%v: want %t, got %t", c.moves, c.want, got)
+		}
+	}
+}
+
+func TestSearcher_Nodes(t *testing.T) {
+	s := newTestSearcher(t, fen.StartingFEN, uci.Go{Nodes: 5000})
+
+	pv := s.iterate(func(uci.Info) {})
+
+	if len(pv) == 0 {
+		t.Fatal("no move found")
+	}
+	if s.nodes > 5000 {
+		t.Errorf("searched %d nodes, want at most 5000", s.nodes)
+	}
+}
+
+func TestUCIScore(t *testing.T) {
+	cases := []struct {
+		in       int
+		want     int
+		wantType string
+	}{
+		{0, 0, uci.ScoreTypeCentipawn},
+		{-150, -150, uci.ScoreTypeCentipawn},
+		{mateScore - 1, 1, uci.ScoreTypeMate},
+		{mateScore - 3, 2, uci.ScoreTypeMate},
+		{-mateScore + 2, -1, uci.ScoreTypeMate},
+		{-mateScore + 4, -2, uci.ScoreTypeMate},
+	}
+
+	for _, c := range cases {
+		got, gotType := uciScore(c.in)
+		if got != c.want || gotType != c.wantType {
+			t.Errorf("%d: want %s %d, got %s %d", c.in, c.wantType, c.want, gotType, got)
+		}
+	}
+}
+
+func TestSearcher_EvaluateClamped(t *testing.T) {
+	for _, w := range []int16{32767, -32768} {
+		// Every hidden neuron saturates, and every output weight is extreme.
+		net := &nnue.Network{
+			Hidden:        4,
+			HiddenWeights: make([]int16, nnue.Inputs*4),
+			HiddenBiases:  []int16{32767, 32767, 32767, 32767},
+			OutputWeights: []int16{w, w, w, w, w, w, w, w},
+		}
+
+		s := newTestSearcher(t, fen.StartingFEN, uci.Go{})
+		s.nn = net.NewEvaluator(&s.pos)
+
+		if raw := s.nn.Evaluate(&s.pos); raw > -mateBound && raw < mateBound {
+			t.Fatalf("weight %d: raw evaluation %d is within bounds", w, raw)
+		}
+		if got := s.evaluate(); got >= mateBound || got <= -mateBound {
+			t.Errorf("weight %d: got %d, want within ±%d", w, got, mateBound-1)
+		}
+	}
+}
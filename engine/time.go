@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/clfs/aloe/chess"
+	"github.com/clfs/aloe/uci"
+)
+
+// Time management parameters.
+const (
+	// defaultMovesToGo is the assumed number of moves left in the game when
+	// there is no "movestogo".
+	defaultMovesToGo = 40
+
+	// maxMovesToGo caps "movestogo", so a long time control still gets a
+	// reasonable share of the clock per move.
+	maxMovesToGo = 50
+
+	// hardFactor is how much longer than the soft budget a search may run.
+	hardFactor = 5
+)
+
+// A timeManager decides how long to search for.
+//
+// The soft budget is checked between iterations: a new iteration starts only
+// if the soft budget, scaled by how unsettled the search is, has not passed.
+// The hard budget is checked during the search and always stops it.
+type timeManager struct {
+	start   time.Time
+	soft    time.Duration
+	hard    time.Duration
+	limited bool // Whether the budgets apply at all.
+}
+
+// newTimeManager returns a time manager for a search started at start by req,
+// with us to move. The overhead is subtracted from the clock up front to cover
+// communication delays.
+func newTimeManager(start time.Time, req *uci.Go, us chess.Color, overhead time.Duration) timeManager {
+	tm := timeManager{start: start}
+
+	// Infinite and ponder searches end only when told to.
+	if req.Infinite || req.Ponder {
+		return tm
+	}
+
+	if req.MoveTime > 0 {
+		tm.limited = true
+		tm.soft = atLeast(ms(req.MoveTime)-overhead, time.Millisecond)
+		tm.hard = tm.soft
+		return tm
+	}
+
+	left, inc := req.WhiteTime, req.WhiteIncrement
+	if us == chess.Black {
+		left, inc = req.BlackTime, req.BlackIncrement
+	}
+	if left <= 0 {
+		return tm
+	}
+
+	tm.limited = true
+
+	mtg := defaultMovesToGo
+	if req.MovesToGo > 0 {
+		mtg = req.MovesToGo
+	}
+	if mtg > maxMovesToGo {
+		mtg = maxMovesToGo
+	}
+
+	remaining := atLeast(ms(left)-overhead, time.Millisecond)
+
+	// Never use more than a fraction of the clock on a single move. With one
+	// move to go, the clock is about to be refilled, so most of it is fair
+	// game. Otherwise, some must be left over for the moves after this one.
+	limit := remaining / 2
+	if mtg == 1 {
+		limit = remaining * 9 / 10
+	}
+
+	tm.soft = atMost(remaining/time.Duration(mtg)+ms(inc)*3/4, limit)
+	tm.hard = atMost(tm.soft*hardFactor, limit)
+
+	return tm
+}
+
+// elapsed returns how long the search has run for.
+func (tm *timeManager) elapsed() time.Duration {
+	return time.Since(tm.start)
+}
+
+// hardExceeded returns true if the search must stop now.
+func (tm *timeManager) hardExceeded() bool {
+	return tm.limited && tm.elapsed() >= tm.hard
+}
+
+// softExceeded returns true if no new iteration should be started. The soft
+// budget is multiplied by scale, so values above 1 extend the search.
+func (tm *timeManager) softExceeded(scale float64) bool {
+	if !tm.limited {
+		return false
+	}
+	soft := atMost(time.Duration(float64(tm.soft)*scale), tm.hard)
+	return tm.elapsed() >= soft
+}
+
+// ms returns n milliseconds as a duration.
+func ms(n int) time.Duration {
+	return time.Duration(n) * time.Millisecond
+}
+
+func atLeast(d, min time.Duration) time.Duration {
+	if d < min {
+		return min
+	}
+	return d
+}
+
+func atMost(d, max time.Duration) time.Duration {
+	if d > max {
+		return max
+	}
+	return d
+}
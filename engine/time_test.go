@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/clfs/aloe/chess"
+	"github.com/clfs/aloe/uci"
+)
+
+func TestNewTimeManager(t *testing.T) {
+	cases := []struct {
+		name        string
+		req         uci.Go
+		us          chess.Color
+		wantLimited bool
+		wantSoft    time.Duration
+		wantHard    time.Duration
+	}{
+		{
+			name: "depth only",
+			req:  uci.Go{Depth: 5},
+		},
+		{
+			name: "infinite",
+			req:  uci.Go{Infinite: true, WhiteTime: 1000},
+		},
+		{
+			name:        "movetime",
+			req:         uci.Go{MoveTime: 1000},
+			wantLimited: true,
+			wantSoft:    990 * time.Millisecond,
+			wantHard:    990 * time.Millisecond,
+		},
+		{
+			name:        "sudden death",
+			req:         uci.Go{WhiteTime: 40010, BlackTime: 1000},
+			us:          chess.White,
+			wantLimited: true,
+			wantSoft:    1000 * time.Millisecond,
+			wantHard:    5000 * time.Millisecond,
+		},
+		{
+			name:        "sudden death as black",
+			req:         uci.Go{WhiteTime: 1000, BlackTime: 40010, BlackIncrement: 400},
+			us:          chess.Black,
+			wantLimited: true,
+			wantSoft:    1300 * time.Millisecond,
+			wantHard:    6500 * time.Millisecond,
+		},
+		{
+			name:        "repeating",
+			req:         uci.Go{WhiteTime: 10010, MovesToGo: 10},
+			us:          chess.White,
+			wantLimited: true,
+			wantSoft:    1000 * time.Millisecond,
+			wantHard:    5000 * time.Millisecond,
+		},
+		{
+			name:        "last move before the time control",
+			req:         uci.Go{WhiteTime: 1010, MovesToGo: 1},
+			us:          chess.White,
+			wantLimited: true,
+			wantSoft:    900 * time.Millisecond,
+			wantHard:    900 * time.Millisecond,
+		},
+		{
+			name:        "increment larger than the clock",
+			req:         uci.Go{WhiteTime: 210, WhiteIncrement: 1000},
+			us:          chess.White,
+			wantLimited: true,
+			wantSoft:    100 * time.Millisecond,
+			wantHard:    100 * time.Millisecond,
+		},
+	}
+
+	for _, c := range cases {
+		tm := newTimeManager(time.Now(), &c.req, c.us, 10*time.Millisecond)
+		if tm.limited != c.wantLimited || tm.soft != c.wantSoft || tm.hard != c.wantHard {
+			t.Errorf("%s: want limited=%t soft=%v hard=%v, got limited=%t soft=%v hard=%v",
+				c.name, c.wantLimited, c.wantSoft, c.wantHard, tm.limited, tm.soft, tm.hard)
+		}
+	}
+}
+
+func TestTimeManager_NeverFlags(t *testing.T) {
+	for _, left := range []int{1, 5, 50, 500, 5000, 50000, 500000} {
+		for _, inc := range []int{0, 10, 1000, 100000} {
+			for _, mtg := range []int{0, 1, 2, 5, 40, 100} {
+				req := uci.Go{WhiteTime: left, WhiteIncrement: inc, MovesToGo: mtg}
+				tm := newTimeManager(time.Now(), &req, chess.White, 0)
+				if tm.hard > ms(left) && left > 1 {
+					t.Errorf("%+v: hard budget %v exceeds the clock", req, tm.hard)
+				}
+				if tm.soft > tm.hard {
+					t.Errorf("%+v: soft budget %v exceeds hard budget %v", req, tm.soft, tm.hard)
+				}
+			}
+		}
+	}
+}
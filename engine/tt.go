@@ -0,0 +1,208 @@
+package engine
+
+import (
+	"math/bits"
+	"sync/atomic"
+
+	"github.com/clfs/aloe/chess"
+)
+
+// Transposition table sizes, in MiB.
+const (
+	defaultHashSize = 16
+	maxHashSize     = 1 << 16
+)
+
+// A bound tells how a stored score relates to the true score of a position.
+type bound uint8
+
+const (
+	boundNone  bound = iota
+	boundLower       // The true score is at least the stored score.
+	boundUpper       // The true score is at most the stored score.
+	boundExact       // The stored score is the true score.
+)
+
+// A ttEntry is a transposition table entry. It is made of two words, both
+// accessed atomically. The first holds the key XORed with the second, so that
+// an entry torn by concurrent writes fails verification instead of returning
+// another position's data.
+//
+// See https://www.chessprogramming.org/Shared_Hash_Table#Lockless.
+type ttEntry struct {
+	check atomic.Uint64
+	data  atomic.Uint64
+}
+
+// ttEntrySize is the size of a ttEntry in bytes.
+const ttEntrySize = 16
+
+// ttData is the unpacked data of a transposition table entry.
+type ttData struct {
+	move  chess.Move
+	score int // Adjusted for mate distance from the stored position.
+	depth int
+	bound bound
+	age   uint16
+}
+
+// pack packs d into a word:
+//
+//	bits  0-15: move
+//	bits 16-31: score
+//	bits 32-39: depth
+//	bits 40-47: bound
+//	bits 48-63: age
+func (d ttData) pack() uint64 {
+	return packMove(d.move) |
+		uint64(uint16(int16(d.score)))<<16 |
+		uint64(uint8(d.depth))<<32 |
+		uint64(d.bound)<<40 |
+		uint64(d.age)<<48
+}
+
+// unpackTTData reverses [ttData.pack].
+func unpackTTData(w uint64) ttData {
+	return ttData{
+		move:  unpackMove(uint16(w)),
+		score: int(int16(w >> 16)),
+		depth: int(uint8(w >> 32)),
+		bound: bound(w >> 40),
+		age:   uint16(w >> 48),
+	}
+}
+
+func packMove(m chess.Move) uint64 {
+	return uint64(m.From) | uint64(m.To)<<6 | uint64(m.PromotionInfo)<<12
+}
+
+func unpackMove(w uint16) chess.Move {
+	return chess.Move{
+		From:          chess.Square(w & 0x3F),
+		To:            chess.Square(w >> 6 & 0x3F),
+		PromotionInfo: chess.PromotionInfo(w >> 12),
+	}
+}
+
+// A transpositionTable caches search results by Zobrist hash. It is safe for
+// concurrent use, except for resize and clear.
+type transpositionTable struct {
+	entries []ttEntry
+	age     uint16 // Incremented for every search. Old entries are replaced first.
+}
+
+// newTranspositionTable returns a transposition table of about mib MiB.
+func newTranspositionTable(mib int) *transpositionTable {
+	t := new(transpositionTable)
+	t.resize(mib)
+	return t
+}
+
+// resize resizes the table to about mib MiB, clearing it.
+func (t *transpositionTable) resize(mib int) {
+	t.entries = make([]ttEntry, mib<<20/ttEntrySize)
+}
+
+// clear clears the table.
+func (t *transpositionTable) clear() {
+	for i := range t.entries {
+		t.entries[i].check.Store(0)
+		t.entries[i].data.Store(0)
+	}
+	t.age = 0
+}
+
+// newSearch marks the start of a new search. When the age wraps around, the
+// table is cleared, since entries written 65536 searches ago would otherwise
+// look current.
+func (t *transpositionTable) newSearch() {
+	t.age++
+	if t.age == 0 {
+		t.clear()
+	}
+}
+
+// entry returns the entry for a key.
+func (t *transpositionTable) entry(key uint64) *ttEntry {
+	// Map the key onto the table with a multiplication rather than a
+	// modulo, which is slower and doesn't need a power of two size.
+	hi, _ := bits.Mul64(key, uint64(len(t.entries)))
+	return &t.entries[hi]
+}
+
+// probe returns the data stored for a key, if any.
+func (t *transpositionTable) probe(key uint64) (ttData, bool) {
+	e := t.entry(key)
+	data := e.data.Load()
+	if data == 0 || e.check.Load()^data != key {
+		return ttData{}, false
+	}
+	return unpackTTData(data), true
+}
+
+// store stores data for a key. Entries from the current search are only
+// replaced by deeper or exact results, or results for a different position.
+func (t *transpositionTable) store(key uint64, d ttData) {
+	e := t.entry(key)
+
+	if old := e.data.Load(); old != 0 && e.check.Load()^old == key {
+		o := unpackTTData(old)
+		if o.age == t.age && d.depth < o.depth && d.bound != boundExact {
+			return
+		}
+		// Keep the old move rather than none at all.
+		if d.move == (chess.Move{}) {
+			d.move = o.move
+		}
+	}
+
+	d.age = t.age
+	data := d.pack()
+	e.check.Store(key ^ data)
+	e.data.Store(data)
+}
+
+// hashfull returns how full the table is in permille, estimated by sampling
+// the entries written during the current search.
+func (t *transpositionTable) hashfull() int {
+	n := len(t.entries)
+	if n > 1000 {
+		n = 1000
+	}
+	if n == 0 {
+		return 0
+	}
+
+	used := 0
+	for i := 0; i < n; i++ {
+		if data := t.entries[i].data.Load(); data != 0 && unpackTTData(data).age == t.age {
+			used++
+		}
+	}
+	return used * 1000 / n
+}
+
+// scoreToTT converts a search score at ply into a score relative to the
+// stored position, so that mate scores stay correct wherever it is reached.
+func scoreToTT(score, ply int) int {
+	switch {
+	case score >= mateBound:
+		return score + ply
+	case score <= -mateBound:
+		return score - ply
+	default:
+		return score
+	}
+}
+
+// scoreFromTT reverses [scoreToTT].
+func scoreFromTT(score, ply int) int {
+	switch {
+	case score >= mateBound:
+		return score - ply
+	case score <= -mateBound:
+		return score + ply
+	default:
+		return score
+	}
+}
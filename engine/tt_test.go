@@ -0,0 +1,116 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/clfs/aloe/chess"
+)
+
+func TestTTData_Pack(t *testing.T) {
+	cases := []ttData{
+		{move: chess.Move{From: chess.E2, To: chess.E4}, score: 35, depth: 7, bound: boundExact, age: 3},
+		{move: chess.Move{From: chess.H7, To: chess.H8, PromotionInfo: chess.QueenPromotion}, score: -mateScore + 5, depth: 1, bound: boundUpper},
+		{score: mateScore - 1, depth: 255, bound: boundLower, age: 255},
+		{score: -1, depth: 9, bound: boundExact, age: 0xFFFF},
+	}
+
+	for _, want := range cases {
+		if got := unpackTTData(want.pack()); got != want {
+			t.Errorf("want %+v, got %+v", want, got)
+		}
+	}
+}
+
+func TestTranspositionTable(t *testing.T) {
+	tt := newTranspositionTable(1)
+	tt.newSearch()
+
+	pos := chess.NewPosition()
+	key := pos.Hash()
+	want := ttData{move: chess.Move{From: chess.G1, To: chess.F3}, score: 12, depth: 4, bound: boundExact}
+
+	if _, ok := tt.probe(key); ok {
+		t.Fatal("probe hit in an empty table")
+	}
+
+	tt.store(key, want)
+
+	got, ok := tt.probe(key)
+	want.age = tt.age
+	if !ok || got != want {
+		t.Errorf("want %+v, got %+v, %t", want, got, ok)
+	}
+
+	// A shallower result from the same search doesn't replace a deeper one.
+	tt.store(key, ttData{score: 99, depth: 2, bound: boundLower})
+	if got, _ := tt.probe(key); got != want {
+		t.Errorf("shallow store: want %+v, got %+v", want, got)
+	}
+
+	// A colliding key is rejected.
+	if _, ok := tt.probe(key ^ 1<<63); ok {
+		t.Error("probe hit for a different key")
+	}
+
+	tt.clear()
+	if _, ok := tt.probe(key); ok {
+		t.Error("probe hit after clear")
+	}
+}
+
+func TestTranspositionTable_AgeWraps(t *testing.T) {
+	tt := newTranspositionTable(1)
+	tt.newSearch()
+
+	pos := chess.NewPosition()
+	key := pos.Hash()
+	tt.store(key, ttData{score: 12, depth: 4, bound: boundExact})
+
+	// Once the age comes around again, the entry must not look current.
+	for i := 0; i < 1<<16; i++ {
+		tt.newSearch()
+	}
+	if got, ok := tt.probe(key); ok && got.age == tt.age {
+		t.Errorf("entry from %d searches ago looks current: %+v", 1<<16, got)
+	}
+	if got := tt.hashfull(); got != 0 {
+		t.Errorf("hashfull: want 0, got %d", got)
+	}
+}
+
+func TestTranspositionTable_HashFull(t *testing.T) {
+	tt := newTranspositionTable(1)
+	tt.newSearch()
+
+	// Store half as many keys as there are entries, which fills about 40% of
+	// the table after collisions.
+	for i := uint64(1); i <= uint64(len(tt.entries)/2); i++ {
+		tt.store(i*0x9E3779B97F4A7C15, ttData{depth: 1, bound: boundExact})
+	}
+
+	if got := tt.hashfull(); got < 300 || got > 500 {
+		t.Errorf("want hashfull around 400, got %d", got)
+	}
+
+	// Entries from earlier searches don't count.
+	tt.newSearch()
+	if got := tt.hashfull(); got != 0 {
+		t.Errorf("want hashfull 0 in a new search, got %d", got)
+	}
+}
+
+func TestScoreTT(t *testing.T) {
+	for _, score := range []int{0, 150, -150, mateScore - 3, -mateScore + 4} {
+		for _, ply := range []int{0, 1, 10} {
+			if got := scoreFromTT(scoreToTT(score, ply), ply); got != score {
+				t.Errorf("score %d at ply %d: round trip gave %d", score, ply, got)
+			}
+		}
+	}
+
+	// A mate in 3 plies found at ply 2 is a mate in 1 ply from the stored
+	// position.
+	if got, want := scoreToTT(mateScore-3, 2), mateScore-1; got != want {
+		t.Errorf("want %d, got %d", want, got)
+	}
+}
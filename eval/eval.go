@@ -0,0 +1,220 @@
+// Package eval implements a hand-crafted static evaluation of chess positions.
+//
+// Every term has a middlegame and an endgame value. The two are blended by the
+// game phase, which is estimated from the non-pawn material left on the board.
+//
+// See [Tapered Eval] for more information.
+//
+// [Tapered Eval]: https://www.chessprogramming.org/Tapered_Eval
+package eval
+
+import "github.com/clfs/aloe/chess"
+
+// A score is a pair of middlegame and endgame values, in centipawns.
+type score struct {
+	mg, eg int
+}
+
+func (s *score) add(o score) {
+	s.mg += o.mg
+	s.eg += o.eg
+}
+
+func (s *score) sub(o score) {
+	s.mg -= o.mg
+	s.eg -= o.eg
+}
+
+func (s score) times(n int) score {
+	return score{s.mg * n, s.eg * n}
+}
+
+// Material values, indexed by [chess.Role]. Kings are never captured.
+var material = [...]score{
+	chess.Pawn:   {80, 100},
+	chess.Knight: {320, 290},
+	chess.Bishop: {330, 300},
+	chess.Rook:   {470, 520},
+	chess.Queen:  {950, 950},
+	chess.King:   {0, 0},
+}
+
+// Mobility bonuses per reachable square, indexed by [chess.Role]. Squares
+// occupied by friendly pieces or attacked by enemy pawns don't count.
+var mobility = [...]score{
+	chess.Knight: {4, 4},
+	chess.Bishop: {5, 5},
+	chess.Rook:   {2, 4},
+	chess.Queen:  {1, 2},
+}
+
+// Pawn structure terms.
+var (
+	doubledPawn  = score{-10, -20} // For each pawn behind another on its file.
+	isolatedPawn = score{-10, -15} // For each pawn with no friendly pawns on neighboring files.
+
+	// Passed pawn bonuses, indexed by rank from the pawn's point of view.
+	passedPawn = [8]score{{0, 0}, {5, 10}, {10, 20}, {15, 35}, {25, 60}, {40, 90}, {60, 130}, {0, 0}}
+)
+
+// King safety terms. They only apply in the middlegame.
+var (
+	pawnShield = 10 // For each friendly pawn in front of the king.
+
+	// Weights of enemy pieces attacking the king zone, per attacked square,
+	// indexed by [chess.Role].
+	kingAttackWeights = [...]int{
+		chess.Knight: 2,
+		chess.Bishop: 2,
+		chess.Rook:   3,
+		chess.Queen:  5,
+	}
+)
+
+// bishopPair is the bonus for having at least two bishops.
+var bishopPair = score{30, 50}
+
+// Game phase weights, indexed by [chess.Role]. The phase runs from maxPhase at
+// the start of the game down to 0 when only kings and pawns remain.
+var (
+	phaseWeights = [...]int{
+		chess.Knight: 1,
+		chess.Bishop: 1,
+		chess.Rook:   2,
+		chess.Queen:  4,
+	}
+	maxPhase = 24
+)
+
+// Evaluate returns a static evaluation of p in centipawns, from the point of
+// view of the side to move.
+func Evaluate(p *chess.Position) int {
+	var s score
+	s.add(evaluateColor(p, chess.White))
+	s.sub(evaluateColor(p, chess.Black))
+
+	phase := Phase(p)
+	v := (s.mg*phase + s.eg*(maxPhase-phase)) / maxPhase
+
+	if p.SideToMove == chess.Black {
+		return -v
+	}
+	return v
+}
+
+// Phase returns the game phase of p, from 24 for the opening down to 0 for a
+// pawn endgame. Promotions can't push it above 24.
+func Phase(p *chess.Position) int {
+	phase := 0
+	for r := chess.Knight; r <= chess.Queen; r++ {
+		bb := p.Board.ByRole(r)
+		phase += phaseWeights[r] * bb.Count()
+	}
+	if phase > maxPhase {
+		phase = maxPhase
+	}
+	return phase
+}
+
+// evaluateColor returns the score of c's pieces.
+func evaluateColor(p *chess.Position, c chess.Color) score {
+	var s score
+
+	b := &p.Board
+	ci := colorIndex(c)
+	ours, theirs := b.ByColor(c), b.ByColor(c.Other())
+	occupied := ours | theirs
+
+	ourPawns := b.ByRole(chess.Pawn) & ours
+	theirPawns := b.ByRole(chess.Pawn) & theirs
+
+	// Squares attacked by enemy pawns are unsafe for our pieces.
+	var unsafe chess.Bitboard
+	for bb := theirPawns; bb != 0; {
+		unsafe |= chess.PawnAttacks(c.Other(), bb.Pop())
+	}
+	area := ^(ours | unsafe)
+
+	// The enemy king zone is the king and the squares around it.
+	theirKing := b.KingOf(c.Other())
+	zone := chess.KingAttacks(theirKing) | theirKing.Bitboard()
+	attackers, attackWeight := 0, 0
+
+	for r := chess.Pawn; r <= chess.King; r++ {
+		for bb := b.ByRole(r) & ours; bb != 0; {
+			sq := bb.Pop()
+
+			idx := int(sq)
+			if c == chess.White {
+				idx ^= 56
+			}
+
+			s.add(material[r])
+			s.add(score{mgTables[r][idx], egTables[r][idx]})
+
+			var attacks chess.Bitboard
+			switch r {
+			case chess.Knight:
+				attacks = chess.KnightAttacks(sq)
+			case chess.Bishop:
+				attacks = chess.BishopAttacks(sq, occupied)
+			case chess.Rook:
+				attacks = chess.RookAttacks(sq, occupied)
+			case chess.Queen:
+				attacks = chess.QueenAttacks(sq, occupied)
+			default:
+				continue
+			}
+
+			reachable := attacks & area
+			s.add(mobility[r].times(reachable.Count()))
+
+			if hits := attacks & zone; hits != 0 {
+				attackers++
+				attackWeight += kingAttackWeights[r] * hits.Count()
+			}
+		}
+	}
+
+	// A lone attacker is rarely dangerous.
+	if attackers >= 2 {
+		s.mg += attackWeight * attackers
+	}
+
+	// Pawn structure.
+	for bb := ourPawns; bb != 0; {
+		sq := bb.Pop()
+		f := sq.File()
+
+		if ourPawns&passedMasks[ci][sq]&fileMasks[f] != 0 {
+			s.add(doubledPawn)
+		}
+
+		if ourPawns&adjacentFiles[f] == 0 {
+			s.add(isolatedPawn)
+		}
+
+		if theirPawns&passedMasks[ci][sq] == 0 && ourPawns&passedMasks[ci][sq]&fileMasks[f] == 0 {
+			s.add(passedPawn[relativeRank(c, sq)])
+		}
+	}
+
+	// King safety.
+	shield := ourPawns & shieldMasks[ci][b.KingOf(c)]
+	s.mg += pawnShield * shield.Count()
+
+	bishops := b.ByRole(chess.Bishop) & ours
+	if bishops.Count() >= 2 {
+		s.add(bishopPair)
+	}
+
+	return s
+}
+
+// relativeRank returns the rank of s from c's point of view, from 0 to 7.
+func relativeRank(c chess.Color, s chess.Square) int {
+	if c == chess.Black {
+		return 7 - int(s.Rank())
+	}
+	return int(s.Rank())
+}
@@ -0,0 +1,173 @@
+package eval
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/clfs/aloe/fen"
+)
+
+// mirror returns the FEN of the position with the board flipped vertically and
+// the colors swapped, which must evaluate the same for the side to move.
+func mirror(s string) string {
+	fields := strings.Fields(s)
+
+	ranks := strings.Split(fields[0], "/")
+	for i, j := 0, len(ranks)-1; i < j; i, j = i+1, j-1 {
+		ranks[i], ranks[j] = ranks[j], ranks[i]
+	}
+	fields[0] = swapCase(strings.Join(ranks, "/"))
+
+	if fields[1] == "w" {
+		fields[1] = "b"
+	} else {
+		fields[1] = "w"
+	}
+
+	if fields[2] != "-" {
+		swapped := swapCase(fields[2])
+		fields[2] = ""
+		for _, r := range "KQkq" {
+			if strings.ContainsRune(swapped, r) {
+				fields[2] += string(r)
+			}
+		}
+	}
+
+	if fields[3] != "-" {
+		rank := '9' - rune(fields[3][1]) + '0'
+		fields[3] = string(fields[3][0]) + string(rank)
+	}
+
+	return strings.Join(fields, " ")
+}
+
+func swapCase(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z':
+			return r - 'a' + 'A'
+		case 'A' <= r && r <= 'Z':
+			return r - 'A' + 'a'
+		default:
+			return r
+		}
+	}, s)
+}
+
+func evaluateFEN(t *testing.T, s string) int {
+	t.Helper()
+	p, err := fen.Decode(s)
+	if err != nil {
+		t.Fatalf("%s: %v", s, err)
+	}
+	return Evaluate(&p)
+}
+
+func TestEvaluate_StartingPosition(t *testing.T) {
+	if got := evaluateFEN(t, fen.StartingFEN); got != 0 {
+		t.Errorf("want 0, got %d", got)
+	}
+}
+
+func TestEvaluate_Symmetry(t *testing.T) {
+	cases := []string{
+		"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		"r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10",
+		"6k1/5ppp/8/3P4/8/8/5PPP/6K1 b - - 0 1",
+	}
+
+	for _, s := range cases {
+		if a, b := evaluateFEN(t, s), evaluateFEN(t, mirror(s)); a != b {
+			t.Errorf("%s: %d, but mirrored %d", s, a, b)
+		}
+	}
+}
+
+func TestEvaluate_SideToMove(t *testing.T) {
+	// White is a queen up.
+	white := evaluateFEN(t, "rnb1kbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+	black := evaluateFEN(t, "rnb1kbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR b KQkq - 0 1")
+
+	if white < 800 {
+		t.Errorf("want at least 800 for White to move, got %d", white)
+	}
+	if black != -white {
+		t.Errorf("want %d for Black to move, got %d", -white, black)
+	}
+}
+
+func TestEvaluate_Terms(t *testing.T) {
+	cases := []struct {
+		name          string
+		better, worse string
+	}{
+		{
+			name:   "passed pawn",
+			better: "6k1/8/8/3P4/8/8/8/6K1 w - - 0 1",
+			worse:  "6k1/3p4/8/3P4/8/8/8/6K1 w - - 0 1",
+		},
+		{
+			name:   "doubled pawns",
+			better: "6k1/pp6/8/8/8/8/PP6/6K1 w - - 0 1",
+			worse:  "6k1/pp6/8/8/8/P7/P7/6K1 w - - 0 1",
+		},
+		{
+			name:   "isolated pawn",
+			better: "6k1/2p2p2/8/8/8/8/2PP4/6K1 w - - 0 1",
+			worse:  "6k1/2p2p2/8/8/8/8/2P2P2/6K1 w - - 0 1",
+		},
+		{
+			name:   "bishop pair",
+			better: "4k3/pppppppp/8/8/8/8/PPPPPPPP/2B1KB2 w - - 0 1",
+			worse:  "4k3/pppppppp/8/8/8/8/PPPPPPPP/2B1KN2 w - - 0 1",
+		},
+		{
+			name:   "pawn shield",
+			better: "r5k1/pppq1ppp/8/8/8/8/PPPQ1PPP/R5K1 w - - 0 1",
+			worse:  "r5k1/pppq1ppp/8/8/8/5P2/PPPQ2PP/R5K1 w - - 0 1",
+		},
+		{
+			name:   "mobility",
+			better: "4k3/8/8/8/3N4/8/8/4K3 w - - 0 1",
+			worse:  "4k3/8/8/8/8/8/8/N3K3 w - - 0 1",
+		},
+	}
+
+	for _, c := range cases {
+		if better, worse := evaluateFEN(t, c.better), evaluateFEN(t, c.worse); better <= worse {
+			t.Errorf("%s: want %d > %d", c.name, better, worse)
+		}
+	}
+}
+
+func TestPhase(t *testing.T) {
+	cases := []struct {
+		fen  string
+		want int
+	}{
+		{fen.StartingFEN, 24},
+		{"4k3/pppppppp/8/8/8/8/PPPPPPPP/4K3 w - - 0 1", 0},
+		{"4k3/8/8/8/8/8/8/R3K3 w - - 0 1", 2},
+		{"QQQQk3/8/8/8/8/8/8/QQQQK3 w - - 0 1", 24},
+	}
+
+	for _, c := range cases {
+		p, err := fen.Decode(c.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := Phase(&p); got != c.want {
+			t.Errorf("%s: want %d, got %d", c.fen, c.want, got)
+		}
+	}
+}
+
+func BenchmarkEvaluate(b *testing.B) {
+	p, _ := fen.Decode("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	for i := 0; i < b.N; i++ {
+		Evaluate(&p)
+	}
+}
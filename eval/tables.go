@@ -0,0 +1,151 @@
+package eval
+
+import "github.com/clfs/aloe/chess"
+
+// Piece-square tables in centipawns, from White's point of view. They are laid
+// out as a board is printed, with the eighth rank first, so a white piece on
+// square s uses index s^56 and a black piece uses index s.
+//
+// The values are adapted from Tomasz Michniewski's Simplified Evaluation
+// Function, with separate endgame tables for pawns and kings.
+//
+// See https://www.chessprogramming.org/Simplified_Evaluation_Function.
+var (
+	pawnMG = [64]int{
+		0, 0, 0, 0, 0, 0, 0, 0,
+		30, 30, 30, 30, 30, 30, 30, 30,
+		10, 10, 20, 30, 30, 20, 10, 10,
+		5, 5, 10, 25, 25, 10, 5, 5,
+		0, 0, 0, 20, 20, 0, 0, 0,
+		5, -5, -10, 0, 0, -10, -5, 5,
+		5, 10, 10, -20, -20, 10, 10, 5,
+		0, 0, 0, 0, 0, 0, 0, 0,
+	}
+	pawnEG = [64]int{
+		0, 0, 0, 0, 0, 0, 0, 0,
+		60, 60, 60, 60, 60, 60, 60, 60,
+		40, 40, 40, 40, 40, 40, 40, 40,
+		25, 25, 25, 25, 25, 25, 25, 25,
+		15, 15, 15, 15, 15, 15, 15, 15,
+		5, 5, 5, 5, 5, 5, 5, 5,
+		0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 0, 0,
+	}
+	knightPST = [64]int{
+		-50, -40, -30, -30, -30, -30, -40, -50,
+		-40, -20, 0, 0, 0, 0, -20, -40,
+		-30, 0, 10, 15, 15, 10, 0, -30,
+		-30, 5, 15, 20, 20, 15, 5, -30,
+		-30, 0, 15, 20, 20, 15, 0, -30,
+		-30, 5, 10, 15, 15, 10, 5, -30,
+		-40, -20, 0, 5, 5, 0, -20, -40,
+		-50, -40, -30, -30, -30, -30, -40, -50,
+	}
+	bishopPST = [64]int{
+		-20, -10, -10, -10, -10, -10, -10, -20,
+		-10, 0, 0, 0, 0, 0, 0, -10,
+		-10, 0, 5, 10, 10, 5, 0, -10,
+		-10, 5, 5, 10, 10, 5, 5, -10,
+		-10, 0, 10, 10, 10, 10, 0, -10,
+		-10, 10, 10, 10, 10, 10, 10, -10,
+		-10, 5, 0, 0, 0, 0, 5, -10,
+		-20, -10, -10, -10, -10, -10, -10, -20,
+	}
+	rookPST = [64]int{
+		0, 0, 0, 0, 0, 0, 0, 0,
+		5, 10, 10, 10, 10, 10, 10, 5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		0, 0, 0, 5, 5, 0, 0, 0,
+	}
+	queenPST = [64]int{
+		-20, -10, -10, -5, -5, -10, -10, -20,
+		-10, 0, 0, 0, 0, 0, 0, -10,
+		-10, 0, 5, 5, 5, 5, 0, -10,
+		-5, 0, 5, 5, 5, 5, 0, -5,
+		0, 0, 5, 5, 5, 5, 0, -5,
+		-10, 5, 5, 5, 5, 5, 0, -10,
+		-10, 0, 5, 0, 0, 0, 0, -10,
+		-20, -10, -10, -5, -5, -10, -10, -20,
+	}
+	kingMG = [64]int{
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-20, -30, -30, -40, -40, -30, -30, -20,
+		-10, -20, -20, -20, -20, -20, -20, -10,
+		20, 20, 0, 0, 0, 0, 20, 20,
+		20, 30, 10, 0, 0, 10, 30, 20,
+	}
+	kingEG = [64]int{
+		-50, -40, -30, -20, -20, -30, -40, -50,
+		-30, -20, -10, 0, 0, -10, -20, -30,
+		-30, -10, 20, 30, 30, 20, -10, -30,
+		-30, -10, 30, 40, 40, 30, -10, -30,
+		-30, -10, 30, 40, 40, 30, -10, -30,
+		-30, -10, 20, 30, 30, 20, -10, -30,
+		-30, -30, 0, 0, 0, 0, -30, -30,
+		-50, -30, -30, -30, -30, -30, -30, -50,
+	}
+)
+
+// Piece-square tables indexed by [chess.Role].
+var (
+	mgTables = [...]*[64]int{&pawnMG, &knightPST, &bishopPST, &rookPST, &queenPST, &kingMG}
+	egTables = [...]*[64]int{&pawnEG, &knightPST, &bishopPST, &rookPST, &queenPST, &kingEG}
+)
+
+// Precomputed masks for pawn structure and king safety.
+var (
+	fileMasks     [8]chess.Bitboard     // All squares on a file.
+	adjacentFiles [8]chess.Bitboard     // All squares on the neighboring files.
+	passedMasks   [2][64]chess.Bitboard // Squares that enemy pawns must avoid for a pawn to be passed, indexed by color and square.
+	shieldMasks   [2][64]chess.Bitboard // Squares in front of a king that its pawns should cover, indexed by color and square.
+)
+
+func init() {
+	for f := chess.FileA; f <= chess.FileH; f++ {
+		for r := chess.Rank1; r <= chess.Rank8; r++ {
+			fileMasks[f].Set(chess.SquareAt(f, r))
+		}
+	}
+
+	for f := chess.FileA; f <= chess.FileH; f++ {
+		if f > chess.FileA {
+			adjacentFiles[f] |= fileMasks[f-1]
+		}
+		if f < chess.FileH {
+			adjacentFiles[f] |= fileMasks[f+1]
+		}
+	}
+
+	for s := chess.A1; s <= chess.H8; s++ {
+		span := fileMasks[s.File()] | adjacentFiles[s.File()]
+		for t := chess.A1; t <= chess.H8; t++ {
+			switch {
+			case t.Rank() > s.Rank() && span.Get(t):
+				passedMasks[colorIndex(chess.White)][s].Set(t)
+				if t.Rank() <= s.Rank()+2 {
+					shieldMasks[colorIndex(chess.White)][s].Set(t)
+				}
+			case t.Rank() < s.Rank() && span.Get(t):
+				passedMasks[colorIndex(chess.Black)][s].Set(t)
+				if t.Rank()+2 >= s.Rank() {
+					shieldMasks[colorIndex(chess.Black)][s].Set(t)
+				}
+			}
+		}
+	}
+}
+
+// colorIndex returns 0 for White and 1 for Black, for use in lookup tables.
+func colorIndex(c chess.Color) int {
+	if c == chess.Black {
+		return 1
+	}
+	return 0
+}
@@ -169,6 +169,8 @@ func Decode(fen string) (chess.Position, error) {
 	}
 	pos.FullMoveNumber = fullMoveNumber
 
+	pos.ResetHash()
+
 	return pos, nil
 }
 
@@ -0,0 +1,309 @@
+// Package nnue implements efficiently updatable neural network (NNUE)
+// evaluation.
+//
+// Networks have a simple, king-agnostic architecture. Each position is encoded
+// from both players' points of view as 768 binary inputs, one per combination
+// of piece color, role and square. A shared hidden layer, the accumulator,
+// turns each encoding into a vector of activations. The accumulators of the
+// side to move and the other side are clipped to [0, QA], concatenated, and
+// reduced to a score by the output layer.
+//
+// Since a move only changes a few inputs, the accumulators are updated
+// incrementally rather than computed from scratch for every position.
+//
+// See [NNUE] for more information.
+//
+// [NNUE]: https://www.chessprogramming.org/NNUE
+package nnue
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/clfs/aloe/chess"
+)
+
+// Quantization constants. Hidden layer values are scaled by QA and output
+// layer weights by QB. Scale converts the network output to centipawns.
+const (
+	QA    = 255
+	QB    = 64
+	Scale = 400
+)
+
+// Inputs is the number of network inputs.
+const Inputs = 768
+
+// MaxHidden is the largest supported hidden layer size.
+const MaxHidden = 4096
+
+// magic identifies network files.
+var magic = [4]byte{'A', 'N', 'N', 'U'}
+
+// version is the network file format version.
+const version = 1
+
+// Network is a quantized NNUE network.
+//
+// A network file is little-endian and contains, in order:
+//
+//   - the magic bytes "ANNU"
+//   - the format version, as a uint32 (currently 1)
+//   - the hidden layer size H, as a uint32
+//   - the hidden layer weights, as 768×H int16 values, grouped by input
+//   - the hidden layer biases, as H int16 values
+//   - the output weights, as 2×H int16 values: H for the side to move,
+//     then H for the other side
+//   - the output bias, as an int32
+type Network struct {
+	Hidden int
+
+	HiddenWeights []int16 // Inputs × Hidden, grouped by input.
+	HiddenBiases  []int16 // Hidden.
+	OutputWeights []int16 // 2 × Hidden.
+	OutputBias    int32
+}
+
+// Load reads a network file.
+func Load(r io.Reader) (*Network, error) {
+	br := bufio.NewReader(r)
+
+	var header struct {
+		Magic   [4]byte
+		Version uint32
+		Hidden  uint32
+	}
+	if err := binary.Read(br, binary.LittleEndian, &header); err != nil {
+		return nil, fmt.Errorf("invalid network: %w", err)
+	}
+	if header.Magic != magic {
+		return nil, fmt.Errorf("invalid network: bad magic %q", header.Magic[:])
+	}
+	if header.Version != version {
+		return nil, fmt.Errorf("invalid network: unsupported version %d", header.Version)
+	}
+	if header.Hidden == 0 || header.Hidden > MaxHidden {
+		return nil, fmt.Errorf("invalid network: bad hidden layer size %d", header.Hidden)
+	}
+
+	n := &Network{Hidden: int(header.Hidden)}
+	n.HiddenWeights = make([]int16, Inputs*n.Hidden)
+	n.HiddenBiases = make([]int16, n.Hidden)
+	n.OutputWeights = make([]int16, 2*n.Hidden)
+
+	for _, data := range []any{n.HiddenWeights, n.HiddenBiases, n.OutputWeights, &n.OutputBias} {
+		if err := binary.Read(br, binary.LittleEndian, data); err != nil {
+			return nil, fmt.Errorf("invalid network: %w", err)
+		}
+	}
+
+	if _, err := br.ReadByte(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid network: trailing data")
+	}
+
+	return n, nil
+}
+
+// Save writes n in the network file format.
+func (n *Network) Save(w io.Writer) error {
+	if err := n.validate(); err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+
+	for _, data := range []any{magic, uint32(version), uint32(n.Hidden),
+		n.HiddenWeights, n.HiddenBiases, n.OutputWeights, n.OutputBias} {
+		if err := binary.Write(bw, binary.LittleEndian, data); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// validate returns an error if the layer sizes of n are inconsistent.
+func (n *Network) validate() error {
+	switch {
+	case n.Hidden <= 0 || n.Hidden > MaxHidden:
+		return fmt.Errorf("invalid network: bad hidden layer size %d", n.Hidden)
+	case len(n.HiddenWeights) != Inputs*n.Hidden,
+		len(n.HiddenBiases) != n.Hidden,
+		len(n.OutputWeights) != 2*n.Hidden:
+		return fmt.Errorf("invalid network: inconsistent layer sizes")
+	}
+	return nil
+}
+
+// input returns the input index of a piece on a square from the point of view
+// of the given player. Each player sees their own pieces first and the board
+// from their own side.
+func input(perspective chess.Color, p chess.Piece, s chess.Square) int {
+	if perspective == chess.Black {
+		s ^= 56
+	}
+	i := int(p.Role)*64 + int(s)
+	if p.Color != perspective {
+		i += 384
+	}
+	return i
+}
+
+// An accumulator holds the hidden layer values, indexed by perspective (White
+// then Black).
+type accumulator [2][]int16
+
+// Evaluator evaluates positions with a network. It keeps a stack of
+// accumulators that follows the moves made and undone in the search.
+//
+// An Evaluator is not safe for concurrent use.
+type Evaluator struct {
+	net   *Network
+	stack []accumulator
+	top   int
+}
+
+// NewEvaluator returns an evaluator for n, set up for position p.
+func (n *Network) NewEvaluator(p *chess.Position) *Evaluator {
+	e := &Evaluator{net: n}
+	e.Reset(p)
+	return e
+}
+
+// Reset computes the accumulator for p from scratch and empties the stack.
+func (e *Evaluator) Reset(p *chess.Position) {
+	e.top = 0
+	acc := e.acc(0)
+
+	for side := 0; side < 2; side++ {
+		copy(acc[side], e.net.HiddenBiases)
+	}
+
+	for s := chess.A1; s <= chess.H8; s++ {
+		if piece, ok := p.Board.At(s); ok {
+			e.add(acc, piece, s)
+		}
+	}
+}
+
+// acc returns the accumulator at index i of the stack, allocating it if
+// needed.
+func (e *Evaluator) acc(i int) *accumulator {
+	for len(e.stack) <= i {
+		e.stack = append(e.stack, accumulator{
+			make([]int16, e.net.Hidden),
+			make([]int16, e.net.Hidden),
+		})
+	}
+	return &e.stack[i]
+}
+
+// add adds the inputs for a piece on a square to acc.
+func (e *Evaluator) add(acc *accumulator, p chess.Piece, s chess.Square) {
+	h := e.net.Hidden
+	for side, perspective := range [2]chess.Color{chess.White, chess.Black} {
+		i := input(perspective, p, s)
+		w := e.net.HiddenWeights[i*h : (i+1)*h]
+		a := acc[side]
+		for j := range a {
+			a[j] += w[j]
+		}
+	}
+}
+
+// sub removes the inputs for a piece on a square from acc.
+func (e *Evaluator) sub(acc *accumulator, p chess.Piece, s chess.Square) {
+	h := e.net.Hidden
+	for side, perspective := range [2]chess.Color{chess.White, chess.Black} {
+		i := input(perspective, p, s)
+		w := e.net.HiddenWeights[i*h : (i+1)*h]
+		a := acc[side]
+		for j := range a {
+			a[j] -= w[j]
+		}
+	}
+}
+
+// Push updates the accumulator for a move. It must be called with the position
+// before the move is made. The move must be legal by the definition of
+// [chess.Position.IsLegalMove]. If not, behavior is undefined.
+func (e *Evaluator) Push(p *chess.Position, m chess.Move) {
+	prev := e.stack[e.top]
+	e.top++
+	acc := e.acc(e.top)
+
+	for side := 0; side < 2; side++ {
+		copy(acc[side], prev[side])
+	}
+
+	mover, _ := p.Board.At(m.From)
+
+	// Captures, including en passant.
+	if captured, ok := p.Board.At(m.To); ok {
+		e.sub(acc, captured, m.To)
+	} else if mover.Role == chess.Pawn && m.From.File() != m.To.File() {
+		victim := chess.SquareAt(m.To.File(), m.From.Rank())
+		e.sub(acc, chess.Piece{Color: mover.Color.Other(), Role: chess.Pawn}, victim)
+	}
+
+	e.sub(acc, mover, m.From)
+
+	landed := mover
+	if r, ok := m.PromotionInfo.Role(); ok {
+		landed.Role = r
+	}
+	e.add(acc, landed, m.To)
+
+	// Castling also moves a rook.
+	if mover.Role == chess.King && (m.To.File()-m.From.File() == 2 || m.From.File()-m.To.File() == 2) {
+		rook := chess.Piece{Color: mover.Color, Role: chess.Rook}
+		rank := m.From.Rank()
+		if m.To.File() == chess.FileG {
+			e.sub(acc, rook, chess.SquareAt(chess.FileH, rank))
+			e.add(acc, rook, chess.SquareAt(chess.FileF, rank))
+		} else {
+			e.sub(acc, rook, chess.SquareAt(chess.FileA, rank))
+			e.add(acc, rook, chess.SquareAt(chess.FileD, rank))
+		}
+	}
+}
+
+// Pop reverts the accumulator to before the last pushed move.
+func (e *Evaluator) Pop() {
+	e.top--
+}
+
+// Evaluate returns the network's evaluation of p in centipawns, from the point
+// of view of the side to move. The accumulator must be up to date with p.
+func (e *Evaluator) Evaluate(p *chess.Position) int {
+	acc := &e.stack[e.top]
+
+	us, them := acc[0], acc[1]
+	if p.SideToMove == chess.Black {
+		us, them = them, us
+	}
+
+	h := e.net.Hidden
+	sum := 0
+	for i := 0; i < h; i++ {
+		sum += crelu(us[i]) * int(e.net.OutputWeights[i])
+		sum += crelu(them[i]) * int(e.net.OutputWeights[h+i])
+	}
+
+	return (sum + int(e.net.OutputBias)) * Scale / (QA * QB)
+}
+
+// crelu is the clipped ReLU activation.
+func crelu(x int16) int {
+	switch {
+	case x < 0:
+		return 0
+	case x > QA:
+		return QA
+	default:
+		return int(x)
+	}
+}
@@ -0,0 +1,132 @@
+package nnue
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/clfs/aloe/chess"
+	"github.com/clfs/aloe/fen"
+	"github.com/google/go-cmp/cmp"
+)
+
+// randomNetwork returns a network with small random weights.
+func randomNetwork(r *rand.Rand, hidden int) *Network {
+	n := &Network{
+		Hidden:        hidden,
+		HiddenWeights: make([]int16, Inputs*hidden),
+		HiddenBiases:  make([]int16, hidden),
+		OutputWeights: make([]int16, 2*hidden),
+		OutputBias:    r.Int31n(2000) - 1000,
+	}
+	for _, s := range [][]int16{n.HiddenWeights, n.HiddenBiases, n.OutputWeights} {
+		for i := range s {
+			s[i] = int16(r.Intn(64) - 32)
+		}
+	}
+	return n
+}
+
+func TestNetwork_SaveLoad(t *testing.T) {
+	want := randomNetwork(rand.New(rand.NewSource(1)), 8)
+
+	var buf bytes.Buffer
+	if err := want.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	b := buf.Bytes()
+
+	got, err := Load(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("(-want, +got)\n%s", diff)
+	}
+
+	bad := map[string][]byte{
+		"empty":     nil,
+		"truncated": b[:len(b)-1],
+		"trailing":  append(append([]byte(nil), b...), 0),
+		"magic":     append([]byte("NNUE"), b[4:]...),
+		"version":   append(append([]byte("ANNU"), 2, 0, 0, 0), b[8:]...),
+		"hidden":    append(append([]byte("ANNU"), 1, 0, 0, 0, 0, 0, 0, 0), b[12:]...),
+	}
+
+	for name, data := range bad {
+		if _, err := Load(bytes.NewReader(data)); err == nil {
+			t.Errorf("%s: expected error, got nil", name)
+		}
+	}
+}
+
+// checkIncremental plays random moves from p and checks that incremental
+// updates match a full refresh.
+func checkIncremental(t *testing.T, r *rand.Rand, n *Network, p *chess.Position, depth int) {
+	t.Helper()
+
+	e := n.NewEvaluator(p)
+
+	var undos []*chess.Undo
+	for i := 0; i < depth; i++ {
+		moves := p.LegalMoves()
+		if len(moves) == 0 || (len(undos) > 0 && r.Intn(4) == 0) {
+			if len(undos) == 0 {
+				return
+			}
+			p.Undo(undos[len(undos)-1])
+			undos = undos[:len(undos)-1]
+			e.Pop()
+		} else {
+			m := moves[r.Intn(len(moves))]
+			e.Push(p, m)
+			undos = append(undos, p.Move(m))
+		}
+
+		fresh := n.NewEvaluator(p)
+		if diff := cmp.Diff(fresh.stack[0], e.stack[e.top]); diff != "" {
+			f, _ := fen.Encode(*p)
+			t.Fatalf("%s: accumulator mismatch (-fresh, +incremental)\n%s", f, diff)
+		}
+		if got, want := e.Evaluate(p), fresh.Evaluate(p); got != want {
+			t.Fatalf("evaluation mismatch: want %d, got %d", want, got)
+		}
+	}
+}
+
+func TestEvaluator_Incremental(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	n := randomNetwork(r, 16)
+
+	fens := []string{
+		fen.StartingFEN,
+		// Castling and promotions.
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"n1n5/PPPk4/8/8/8/8/4Kppp/5N1N b - - 0 1",
+		// En passant.
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+	}
+
+	for _, s := range fens {
+		for i := 0; i < 20; i++ {
+			p, err := fen.Decode(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkIncremental(t, r, n, &p, 60)
+		}
+	}
+}
+
+func TestEvaluator_Symmetry(t *testing.T) {
+	n := randomNetwork(rand.New(rand.NewSource(3)), 16)
+
+	// The same position with colors swapped and the board flipped.
+	a, _ := fen.Decode("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	b, _ := fen.Decode("r3k2r/pppbbppp/2n2q1P/1P2p3/3pn3/BN2PNP1/P1PPQPB1/R3K2R b KQkq - 0 1")
+
+	if ea, eb := n.NewEvaluator(&a).Evaluate(&a), n.NewEvaluator(&b).Evaluate(&b); ea != eb {
+		t.Errorf("want equal evaluations, got %d and %d", ea, eb)
+	}
+}
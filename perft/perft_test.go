@@ -4,8 +4,49 @@ import (
 	"testing"
 
 	"github.com/clfs/aloe/chess"
+	"github.com/clfs/aloe/fen"
 )
 
+// Positions and node counts from https://www.chessprogramming.org/Perft_Results.
+var countTests = []struct {
+	fen   string
+	depth int
+	want  int
+}{
+	{fen.StartingFEN, 5, 4865609},
+	{"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", 4, 4085603},
+	{"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", 5, 674624},
+	{"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1", 4, 422333},
+	{"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8", 4, 2103487},
+	{"r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10", 4, 3894594},
+}
+
+func TestCount(t *testing.T) {
+	for _, tc := range countTests {
+		p, err := fen.Decode(tc.fen)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.fen, err)
+		}
+
+		if got := Count(&p, tc.depth); got != tc.want {
+			t.Errorf("%q: depth %d: got %d, want %d", tc.fen, tc.depth, got, tc.want)
+		}
+	}
+}
+
+func TestDivide(t *testing.T) {
+	p := chess.NewPosition()
+
+	var total int
+	for _, n := range Divide(&p, 3) {
+		total += n
+	}
+
+	if want := 8902; total != want {
+		t.Errorf("got %d, want %d", total, want)
+	}
+}
+
 func BenchmarkCount_5(b *testing.B) {
 	p := chess.NewPosition()
 	b.ResetTimer()
@@ -0,0 +1,126 @@
+// Package pgn implements reading and writing of Portable Game Notation (PGN).
+//
+// Games are read into a tree of [Node] values so that recursive variations,
+// comments and numeric annotation glyphs (NAGs) are preserved. Games are
+// written in PGN export format.
+//
+// See [PGN] for more information.
+//
+// [PGN]: https://www.chessprogramming.org/Portable_Game_Notation
+package pgn
+
+import (
+	"github.com/clfs/aloe/chess"
+	"github.com/clfs/aloe/fen"
+)
+
+// Game termination markers.
+const (
+	WhiteWins  = "1-0"
+	BlackWins  = "0-1"
+	Draw       = "1/2-1/2"
+	Unfinished = "*"
+)
+
+// Tag is a PGN tag pair, like [Event "F/S Return Match"].
+type Tag struct {
+	Name  string
+	Value string
+}
+
+// Game is a PGN game.
+type Game struct {
+	Tags   []Tag  // Tag pairs, in the order they were read or set.
+	Root   *Node  // The starting position. Its children are the first moves.
+	Result string // One of WhiteWins, BlackWins, Draw or Unfinished.
+}
+
+// NewGame returns a new, unfinished game starting from the provided position.
+// If the position is not the standard starting position, the SetUp and FEN
+// tags are set.
+func NewGame(p chess.Position) (*Game, error) {
+	g := &Game{
+		Root:   &Node{Position: p},
+		Result: Unfinished,
+	}
+
+	if p != chess.NewPosition() {
+		s, err := fen.Encode(p)
+		if err != nil {
+			return nil, err
+		}
+		g.SetTag("SetUp", "1")
+		g.SetTag("FEN", s)
+	}
+
+	return g, nil
+}
+
+// Tag returns the value of the named tag, if any.
+func (g *Game) Tag(name string) (string, bool) {
+	for _, t := range g.Tags {
+		if t.Name == name {
+			return t.Value, true
+		}
+	}
+	return "", false
+}
+
+// SetTag sets the value of the named tag, adding it if necessary.
+func (g *Game) SetTag(name, value string) {
+	for i, t := range g.Tags {
+		if t.Name == name {
+			g.Tags[i].Value = value
+			return
+		}
+	}
+	g.Tags = append(g.Tags, Tag{name, value})
+}
+
+// MainLine returns the moves of the main line, oldest first.
+func (g *Game) MainLine() []chess.Move {
+	var moves []chess.Move
+	for n := g.Root; len(n.Children) > 0; {
+		n = n.Children[0]
+		moves = append(moves, n.Move)
+	}
+	return moves
+}
+
+// End returns the last node of the main line.
+func (g *Game) End() *Node {
+	n := g.Root
+	for len(n.Children) > 0 {
+		n = n.Children[0]
+	}
+	return n
+}
+
+// Node is a node in a game tree. Every node except the root is reached by a
+// move from its parent.
+type Node struct {
+	Parent   *Node
+	Children []*Node // The first child continues the line; the rest are variations.
+
+	Move     chess.Move     // The move from the parent. Unset for the root.
+	Position chess.Position // The position after the move.
+
+	PreComments []string // Comments before the move.
+	Comments    []string // Comments after the move. For the root, the game comments.
+	NAGs        []int    // Numeric annotation glyphs for the move.
+}
+
+// AddMove adds a child node reached by a move and returns it. The move must be
+// legal by the definition of [chess.Position.IsLegalMove]. If not, behavior
+// is undefined.
+func (n *Node) AddMove(m chess.Move) *Node {
+	child := &Node{
+		Parent:   n,
+		Move:     m,
+		Position: n.Position,
+	}
+	child.Position.Move(m)
+
+	n.Children = append(n.Children, child)
+	return child
+}
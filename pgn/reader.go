@@ -0,0 +1,512 @@
+package pgn
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/clfs/aloe/chess"
+	"github.com/clfs/aloe/fen"
+	"github.com/clfs/aloe/san"
+)
+
+// tokenKind is the kind of a PGN token.
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenString
+	tokenSymbol
+	tokenPeriod
+	tokenAsterisk
+	tokenLeftBracket
+	tokenRightBracket
+	tokenLeftParen
+	tokenRightParen
+	tokenComment
+	tokenNAG
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// suffixNAGs maps move suffix annotations to their equivalent NAGs.
+var suffixNAGs = map[string]int{
+	"!":  1,
+	"?":  2,
+	"!!": 3,
+	"??": 4,
+	"!?": 5,
+	"?!": 6,
+}
+
+// Reader reads games from a PGN input stream.
+type Reader struct {
+	r     *bufio.Reader
+	line  int // Current line number, starting at 1.
+	bol   bool
+	first rune // First rune of the current line.
+
+	peeked *token
+}
+
+// NewReader returns a new reader that reads from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r), line: 1, bol: true}
+}
+
+// Read reads the next game from the input stream. At the end of the input
+// stream, Read returns nil, io.EOF.
+//
+// If a game is malformed, Read returns an error and skips to the next tag pair
+// section, so that the following call reads the next game.
+func (r *Reader) Read() (*Game, error) {
+	g, err := r.read()
+
+	var se *syntaxError
+	if errors.As(err, &se) {
+		if err := r.skipGame(); err != nil {
+			return nil, err
+		}
+	}
+
+	return g, err
+}
+
+// read reads the next game from the input stream.
+func (r *Reader) read() (*Game, error) {
+	tok, err := r.peek()
+	if err != nil {
+		return nil, err
+	}
+	if tok.kind == tokenEOF {
+		return nil, io.EOF
+	}
+
+	// Read the tag pairs.
+
+	var tags []Tag
+
+	for tok.kind == tokenLeftBracket {
+		r.next()
+
+		name, err := r.expect(tokenSymbol)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := r.expect(tokenString)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := r.expect(tokenRightBracket); err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, Tag{name.text, value.text})
+
+		if tok, err = r.peek(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Set up the starting position.
+
+	pos := chess.NewPosition()
+
+	g := &Game{Tags: tags}
+	if s, ok := g.Tag("FEN"); ok {
+		if pos, err = fen.Decode(s); err != nil {
+			return nil, r.errorf("invalid FEN tag: %v", err)
+		}
+		if err := pos.IsValid(); err != nil {
+			return nil, r.errorf("invalid FEN tag: %v", err)
+		}
+	}
+
+	g.Root = &Node{Position: pos}
+
+	// Read the movetext.
+
+	var (
+		cur     = g.Root
+		stack   []*Node  // Nodes to return to at the end of each variation.
+		fresh   = true   // Whether no move has been read in this line yet.
+		pending []string // Comments before the first move of a variation.
+	)
+
+	for {
+		tok, err := r.next()
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok.kind {
+		case tokenEOF, tokenLeftBracket:
+			// A left bracket starts the next game, so leave it to be read.
+			r.peeked = &tok
+			return nil, r.errorf("missing game termination marker")
+
+		case tokenAsterisk:
+			g.Result = Unfinished
+
+		case tokenSymbol:
+			switch tok.text {
+			case WhiteWins, BlackWins, Draw:
+				g.Result = tok.text
+			}
+
+		case tokenPeriod:
+			continue
+
+		case tokenComment:
+			if fresh && len(stack) > 0 {
+				pending = append(pending, tok.text)
+			} else {
+				cur.Comments = append(cur.Comments, tok.text)
+			}
+			continue
+
+		case tokenNAG:
+			n, err := strconv.Atoi(tok.text)
+			if err != nil || cur == g.Root {
+				return nil, r.errorf("invalid NAG: $%s", tok.text)
+			}
+			cur.NAGs = append(cur.NAGs, n)
+			continue
+
+		case tokenLeftParen:
+			if cur.Parent == nil {
+				return nil, r.errorf("variation before the first move")
+			}
+			stack = append(stack, cur)
+			cur, fresh = cur.Parent, true
+			continue
+
+		case tokenRightParen:
+			if len(stack) == 0 {
+				return nil, r.errorf("unmatched )")
+			}
+			cur, stack = stack[len(stack)-1], stack[:len(stack)-1]
+			fresh, pending = false, nil
+			continue
+
+		default:
+			return nil, r.errorf("unexpected token %q", tok.text)
+		}
+
+		if g.Result != "" {
+			if len(stack) > 0 {
+				return nil, r.errorf("unterminated variation")
+			}
+			return g, nil
+		}
+
+		// The symbol is a move number or a move.
+
+		if isMoveNumber(tok.text) {
+			continue
+		}
+
+		text, suffix := splitSuffix(tok.text)
+
+		m, err := san.Decode(cur.Position, text)
+		if err != nil {
+			return nil, r.errorf("%v", err)
+		}
+
+		cur = cur.AddMove(m)
+		cur.PreComments, pending = pending, nil
+		fresh = false
+
+		if suffix != "" {
+			nag, ok := suffixNAGs[suffix]
+			if !ok {
+				return nil, r.errorf("invalid move suffix: %s", suffix)
+			}
+			cur.NAGs = append(cur.NAGs, nag)
+		}
+	}
+}
+
+// isMoveNumber returns true if s is made up of digits only.
+func isMoveNumber(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// splitSuffix splits a move like "Nf3!?" into the move and its suffix
+// annotation.
+func splitSuffix(s string) (move, suffix string) {
+	i := strings.IndexAny(s, "!?")
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], s[i:]
+}
+
+// syntaxError is an error in the PGN input, as opposed to an error reading it.
+type syntaxError struct {
+	line int
+	msg  string
+}
+
+func (e *syntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.line, e.msg)
+}
+
+// errorf returns a syntax error annotated with the current line number.
+func (r *Reader) errorf(format string, a ...any) error {
+	return &syntaxError{r.line, fmt.Sprintf(format, a...)}
+}
+
+// skipGame skips the rest of a malformed game, up to the next line that starts
+// a tag pair section: a line starting with a left bracket that doesn't follow
+// another tag pair line.
+func (r *Reader) skipGame() error {
+	if r.peeked != nil && r.peeked.kind == tokenLeftBracket {
+		return nil
+	}
+	r.peeked = nil
+
+	inTags := r.first == '['
+	if !r.bol {
+		if err := r.skipLine(); err != nil {
+			return ignoreEOF(err)
+		}
+	}
+
+	for {
+		c, err := r.readRune()
+		if err != nil {
+			return ignoreEOF(err)
+		}
+		if c == '[' && !inTags {
+			r.unreadRune(c)
+			return nil
+		}
+		inTags = c == '['
+		if c != '\n' {
+			if err := r.skipLine(); err != nil {
+				return ignoreEOF(err)
+			}
+		}
+	}
+}
+
+// ignoreEOF returns err, or nil if err is io.EOF.
+func ignoreEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+// expect returns the next token, which must be of the given kind.
+func (r *Reader) expect(kind tokenKind) (token, error) {
+	tok, err := r.next()
+	if err != nil {
+		return tok, err
+	}
+	if tok.kind != kind {
+		return tok, r.errorf("unexpected token %q", tok.text)
+	}
+	return tok, nil
+}
+
+// peek returns the next token without consuming it.
+func (r *Reader) peek() (token, error) {
+	if r.peeked == nil {
+		tok, err := r.scan()
+		if err != nil {
+			return tok, err
+		}
+		r.peeked = &tok
+	}
+	return *r.peeked, nil
+}
+
+// next consumes and returns the next token.
+func (r *Reader) next() (token, error) {
+	if r.peeked != nil {
+		tok := *r.peeked
+		r.peeked = nil
+		return tok, nil
+	}
+	return r.scan()
+}
+
+// readRune reads a rune and keeps track of line numbers.
+func (r *Reader) readRune() (rune, error) {
+	c, _, err := r.r.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	if r.bol {
+		r.first = c
+	}
+	r.bol = c == '\n'
+	if c == '\n' {
+		r.line++
+	}
+	return c, nil
+}
+
+// unreadRune unreads the last rune read, c.
+func (r *Reader) unreadRune(c rune) {
+	_ = r.r.UnreadRune()
+	if c == '\n' {
+		r.line--
+	}
+	r.bol = false
+}
+
+// skipLine skips the rest of the current line.
+func (r *Reader) skipLine() error {
+	for {
+		c, err := r.readRune()
+		if err != nil || c == '\n' {
+			return err
+		}
+	}
+}
+
+// scan reads the next token from the input.
+func (r *Reader) scan() (token, error) {
+	for {
+		bol := r.bol
+
+		c, err := r.readRune()
+		if errors.Is(err, io.EOF) {
+			return token{kind: tokenEOF}, nil
+		} else if err != nil {
+			return token{}, err
+		}
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			continue
+
+		case c == '%' && bol:
+			// An escaped line, which is ignored.
+			if err := r.skipLine(); err != nil && !errors.Is(err, io.EOF) {
+				return token{}, err
+			}
+			continue
+
+		case c == ';':
+			text, err := r.scanWhile(func(c rune) bool { return c != '\n' })
+			return token{tokenComment, strings.TrimSpace(text)}, err
+
+		case c == '[':
+			return token{tokenLeftBracket, "["}, nil
+		case c == ']':
+			return token{tokenRightBracket, "]"}, nil
+		case c == '(':
+			return token{tokenLeftParen, "("}, nil
+		case c == ')':
+			return token{tokenRightParen, ")"}, nil
+		case c == '.':
+			return token{tokenPeriod, "."}, nil
+		case c == '*':
+			return token{tokenAsterisk, "*"}, nil
+
+		case c == '"':
+			return r.scanString()
+
+		case c == '{':
+			return r.scanComment()
+
+		case c == '$':
+			digits, err := r.scanWhile(func(c rune) bool { return '0' <= c && c <= '9' })
+			return token{tokenNAG, digits}, err
+
+		case isSymbolStart(c):
+			r.unreadRune(c)
+			text, err := r.scanWhile(isSymbolContinue)
+			return token{tokenSymbol, text}, err
+
+		default:
+			return token{}, r.errorf("unexpected character %q", c)
+		}
+	}
+}
+
+// scanWhile reads runes while f returns true.
+func (r *Reader) scanWhile(f func(rune) bool) (string, error) {
+	var b strings.Builder
+	for {
+		c, err := r.readRune()
+		if errors.Is(err, io.EOF) {
+			return b.String(), nil
+		} else if err != nil {
+			return "", err
+		}
+		if !f(c) {
+			r.unreadRune(c)
+			return b.String(), nil
+		}
+		b.WriteRune(c)
+	}
+}
+
+// scanString reads a string token. The opening quote is already consumed.
+func (r *Reader) scanString() (token, error) {
+	var b strings.Builder
+	for {
+		c, err := r.readRune()
+		if errors.Is(err, io.EOF) {
+			return token{}, r.errorf("unterminated string")
+		} else if err != nil {
+			return token{}, err
+		}
+
+		switch c {
+		case '"':
+			return token{tokenString, b.String()}, nil
+		case '\\':
+			if c, err = r.readRune(); err != nil {
+				return token{}, r.errorf("unterminated string")
+			}
+		}
+
+		b.WriteRune(c)
+	}
+}
+
+// scanComment reads a brace comment. The opening brace is already consumed.
+func (r *Reader) scanComment() (token, error) {
+	var b strings.Builder
+	for {
+		c, err := r.readRune()
+		if errors.Is(err, io.EOF) {
+			return token{}, r.errorf("unterminated comment")
+		} else if err != nil {
+			return token{}, err
+		}
+
+		if c == '}' {
+			return token{tokenComment, strings.TrimSpace(b.String())}, nil
+		}
+
+		b.WriteRune(c)
+	}
+}
+
+func isSymbolStart(c rune) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+func isSymbolContinue(c rune) bool {
+	return isSymbolStart(c) || strings.ContainsRune("_+#=:-/!?", c)
+}
@@ -0,0 +1,231 @@
+package pgn
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/clfs/aloe/chess"
+	"github.com/google/go-cmp/cmp"
+)
+
+// fischerSpassky is the example game from the PGN standard.
+const fischerSpassky = `[Event "F/S Return Match"]
+[Site "Belgrade, Serbia JUG"]
+[Date "1992.11.04"]
+[Round "29"]
+[White "Fischer, Robert J."]
+[Black "Spassky, Boris V."]
+[Result "1/2-1/2"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bb5 a6 {This opening is called the Ruy Lopez.} 4. Ba4
+Nf6 5. O-O Be7 6. Re1 b5 7. Bb3 d6 8. c3 O-O 9. h3 Nb8 10. d4 Nbd7 11. c4 c6
+12. cxb5 axb5 13. Nc3 Bb7 14. Bg5 b4 15. Nb1 h6 16. Bh4 c5 17. dxe5 Nxe4 18.
+Bxe7 Qxe7 19. exd6 Qf6 20. Nbd2 Nxd6 21. Nc4 Nxc4 22. Bxc4 Nb6 23. Ne5 Rae8 24.
+Bxf7+ Rxf7 25. Nxf7 Rxe1+ 26. Qxe1 Kxf7 27. Qe3 Qg5 28. Qxg5 hxg5 29. b3 Ke6
+30. a3 Kd6 31. axb4 cxb4 32. Ra5 Nd5 33. f3 Bc8 34. Kf2 Bf5 35. Ra7 g6 36. Ra6+
+Kc5 37. Ke1 Nf4 38. g3 Nxh3 39. Kd2 Kb5 40. Rd6 Kc5 41. Ra6 Nf2 42. g4 Bd3 43.
+Re6 1/2-1/2
+
+`
+
+func TestReader_Read(t *testing.T) {
+	r := NewReader(strings.NewReader(fischerSpassky))
+
+	g, err := r.Read()
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if got, want := len(g.Tags), 7; got != want {
+		t.Errorf("got %d tags, want %d", got, want)
+	}
+	if got, _ := g.Tag("White"); got != "Fischer, Robert J." {
+		t.Errorf("got White tag %q", got)
+	}
+	if g.Result != Draw {
+		t.Errorf("got result %q, want %q", g.Result, Draw)
+	}
+	if got, want := len(g.MainLine()), 85; got != want {
+		t.Errorf("got %d moves, want %d", got, want)
+	}
+
+	// The comment follows Black's third move.
+	n := g.Root
+	for i := 0; i < 6; i++ {
+		n = n.Children[0]
+	}
+	if diff := cmp.Diff([]string{"This opening is called the Ruy Lopez."}, n.Comments); diff != "" {
+		t.Errorf("comments (-want, +got)\n%s", diff)
+	}
+
+	if _, err := r.Read(); !errors.Is(err, io.EOF) {
+		t.Errorf("got %v, want io.EOF", err)
+	}
+}
+
+func TestReader_Read_Variations(t *testing.T) {
+	const text = `[Event "?"]
+
+{Game comment} 1. e4 $1 (1. d4 {Queen's pawn} d5 (1... Nf6 2. c4) 2. c4) (1. c4)
+1... c5!? ; rest of line
+2. Nf3 *`
+
+	g, err := NewReader(strings.NewReader(text)).Read()
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"Game comment"}, g.Root.Comments); diff != "" {
+		t.Errorf("root comments (-want, +got)\n%s", diff)
+	}
+	if g.Result != Unfinished {
+		t.Errorf("got result %q, want %q", g.Result, Unfinished)
+	}
+	if got, want := len(g.Root.Children), 3; got != want {
+		t.Fatalf("got %d first moves, want %d", got, want)
+	}
+
+	e4 := g.Root.Children[0]
+	if diff := cmp.Diff([]int{1}, e4.NAGs); diff != "" {
+		t.Errorf("e4 NAGs (-want, +got)\n%s", diff)
+	}
+
+	c5 := e4.Children[0]
+	if diff := cmp.Diff([]int{5}, c5.NAGs); diff != "" {
+		t.Errorf("c5 NAGs (-want, +got)\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"rest of line"}, c5.Comments); diff != "" {
+		t.Errorf("c5 comments (-want, +got)\n%s", diff)
+	}
+
+	d4 := g.Root.Children[1]
+	if got, want := len(d4.Children), 2; got != want {
+		t.Errorf("got %d replies to d4, want %d", got, want)
+	}
+	if diff := cmp.Diff([]string{"Queen's pawn"}, d4.Comments); diff != "" {
+		t.Errorf("d4 comments (-want, +got)\n%s", diff)
+	}
+
+	want := []chess.Move{
+		{From: chess.E2, To: chess.E4},
+		{From: chess.C7, To: chess.C5},
+		{From: chess.G1, To: chess.F3},
+	}
+	if diff := cmp.Diff(want, g.MainLine()); diff != "" {
+		t.Errorf("main line (-want, +got)\n%s", diff)
+	}
+}
+
+func TestReader_Read_FEN(t *testing.T) {
+	const text = `[SetUp "1"]
+[FEN "4k3/8/8/8/8/8/4P3/4K3 b - - 0 1"]
+
+1... Kd7 2. e4 1-0
+`
+
+	g, err := NewReader(strings.NewReader(text)).Read()
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if got, want := len(g.MainLine()), 2; got != want {
+		t.Errorf("got %d moves, want %d", got, want)
+	}
+}
+
+func TestReader_Read_Multiple(t *testing.T) {
+	text := fischerSpassky + "% escaped line\n" + fischerSpassky
+
+	r := NewReader(strings.NewReader(text))
+
+	var n int
+	for {
+		_, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatalf("game %d: error: %v", n+1, err)
+		}
+		n++
+	}
+
+	if n != 2 {
+		t.Errorf("got %d games, want 2", n)
+	}
+}
+
+func TestReader_Read_Invalid(t *testing.T) {
+	cases := []string{
+		`[Event "?"`,
+		`[Event ?]`,
+		`1. e4 e5`,
+		`1. e5 *`,
+		`1. e4 (1. d4 *`,
+		`1. e4 ) *`,
+		`( 1. e4 ) *`,
+		`1. e4 {unterminated *`,
+		`1. e4 $x *`,
+		`1. e4 @ *`,
+		`[FEN "bad"] *`,
+		`[FEN "8/8/8/8/8/8/8/8 w - - 0 1"] *`,
+	}
+	for _, text := range cases {
+		if _, err := NewReader(strings.NewReader(text)).Read(); err == nil {
+			t.Errorf("%q: read invalid PGN", text)
+		}
+	}
+}
+
+func TestReader_Read_Resync(t *testing.T) {
+	cases := []struct {
+		name string
+		bad  string
+	}{
+		{"bad move", "[Event \"Bad\"]\n\n1. e4 e4 2. Nf3 *\n"},
+		{"bad tag", "[Event \"Bad]\n[Site \"?\"]\n\n1. e4 *\n"},
+		{"bad character", "[Event \"Bad\"]\n\n1. e4 @ e5\n2. Nf3 *\n"},
+		{"missing result", "[Event \"Bad\"]\n\n1. e4 e5\n"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			text := "[Event \"First\"]\n\n1. d4 *\n\n" + tc.bad + "\n[Event \"Last\"]\n\n1. c4 *\n"
+			r := NewReader(strings.NewReader(text))
+
+			var events []string
+			var errs int
+			for {
+				g, err := r.Read()
+				if errors.Is(err, io.EOF) {
+					break
+				} else if err != nil {
+					errs++
+					continue
+				}
+				event, _ := g.Tag("Event")
+				events = append(events, event)
+			}
+
+			if errs != 1 {
+				t.Errorf("got %d errors, want 1", errs)
+			}
+			if diff := cmp.Diff([]string{"First", "Last"}, events); diff != "" {
+				t.Errorf("events: (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func FuzzReader(f *testing.F) {
+	f.Add(fischerSpassky)
+	f.Fuzz(func(t *testing.T, text string) {
+		r := NewReader(strings.NewReader(text))
+		for i := 0; i < 100; i++ {
+			if _, err := r.Read(); errors.Is(err, io.EOF) {
+				break
+			}
+		}
+	})
+}
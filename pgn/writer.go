@@ -0,0 +1,198 @@
+package pgn
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/clfs/aloe/chess"
+	"github.com/clfs/aloe/san"
+)
+
+// maxLineLength is the maximum length of a movetext line in export format.
+const maxLineLength = 79
+
+// sevenTagRoster lists the tags that every exported game has, in order, with
+// their default values.
+var sevenTagRoster = []Tag{
+	{"Event", "?"},
+	{"Site", "?"},
+	{"Date", "????.??.??"},
+	{"Round", "?"},
+	{"White", "?"},
+	{"Black", "?"},
+	{"Result", Unfinished},
+}
+
+// Writer writes games in PGN export format.
+type Writer struct {
+	w io.Writer
+}
+
+// NewWriter returns a new writer that writes to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Write writes a game, followed by a blank line.
+func (w *Writer) Write(g *Game) error {
+	switch g.Result {
+	case WhiteWins, BlackWins, Draw, Unfinished:
+	default:
+		return fmt.Errorf("invalid result: %q", g.Result)
+	}
+
+	var b strings.Builder
+
+	// Write the tag pairs, starting with the Seven Tag Roster.
+
+	for _, t := range sevenTagRoster {
+		value, ok := g.Tag(t.Name)
+		if !ok {
+			value = t.Value
+		}
+		if t.Name == "Result" {
+			value = g.Result
+		}
+		writeTag(&b, t.Name, value)
+	}
+
+	for _, t := range g.Tags {
+		if !isSevenTagRoster(t.Name) {
+			writeTag(&b, t.Name, t.Value)
+		}
+	}
+
+	b.WriteString("\n")
+
+	// Write the movetext.
+
+	var tokens []string
+
+	for _, c := range g.Root.Comments {
+		tokens = appendComment(tokens, c)
+	}
+
+	tokens, err := appendLine(tokens, g.Root, true)
+	if err != nil {
+		return err
+	}
+
+	tokens = append(tokens, g.Result)
+
+	n := 0
+	for _, tok := range tokens {
+		switch {
+		case n == 0:
+		case n+1+len(tok) > maxLineLength:
+			b.WriteString("\n")
+			n = 0
+		default:
+			b.WriteString(" ")
+			n++
+		}
+		b.WriteString(tok)
+		n += len(tok)
+	}
+
+	b.WriteString("\n\n")
+
+	_, err = io.WriteString(w.w, b.String())
+	return err
+}
+
+// appendLine appends the tokens for the line that continues from n, along
+// with any variations along the way. A move number is needed for Black's move
+// at the start of a line and after a comment or variation; number reports
+// whether the first move is one of those.
+func appendLine(tokens []string, n *Node, number bool) ([]string, error) {
+	var err error
+
+	for len(n.Children) > 0 {
+		main := n.Children[0]
+
+		if tokens, err = appendMove(tokens, n, main, number); err != nil {
+			return nil, err
+		}
+		number = len(main.Comments) > 0
+
+		// Parentheses are attached to the first and last tokens of each
+		// variation.
+		for _, v := range n.Children[1:] {
+			start := len(tokens)
+			if tokens, err = appendMove(tokens, n, v, true); err != nil {
+				return nil, err
+			}
+			if tokens, err = appendLine(tokens, v, len(v.Comments) > 0); err != nil {
+				return nil, err
+			}
+			tokens[start] = "(" + tokens[start]
+			tokens[len(tokens)-1] += ")"
+			number = true
+		}
+
+		n = main
+	}
+
+	return tokens, nil
+}
+
+// appendMove appends the tokens for the move from parent to child.
+func appendMove(tokens []string, parent, child *Node, number bool) ([]string, error) {
+	for _, c := range child.PreComments {
+		tokens = appendComment(tokens, c)
+	}
+
+	p := parent.Position
+	switch {
+	case p.SideToMove == chess.White:
+		tokens = append(tokens, fmt.Sprintf("%d.", p.FullMoveNumber))
+	case number || len(child.PreComments) > 0:
+		tokens = append(tokens, fmt.Sprintf("%d...", p.FullMoveNumber))
+	}
+
+	text, err := san.Encode(p, child.Move)
+	if err != nil {
+		return nil, err
+	}
+	tokens = append(tokens, text)
+
+	for _, nag := range child.NAGs {
+		tokens = append(tokens, fmt.Sprintf("$%d", nag))
+	}
+
+	for _, c := range child.Comments {
+		tokens = appendComment(tokens, c)
+	}
+
+	return tokens, nil
+}
+
+// appendComment appends a brace comment, split into words so that it can be
+// wrapped across lines.
+func appendComment(tokens []string, c string) []string {
+	words := strings.Fields(strings.ReplaceAll(c, "}", ""))
+	if len(words) == 0 {
+		return append(tokens, "{}")
+	}
+
+	words[0] = "{" + words[0]
+	words[len(words)-1] += "}"
+
+	return append(tokens, words...)
+}
+
+func writeTag(b *strings.Builder, name, value string) {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	fmt.Fprintf(b, "[%s \"%s\"]\n", name, value)
+}
+
+func isSevenTagRoster(name string) bool {
+	for _, t := range sevenTagRoster {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
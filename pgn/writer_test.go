@@ -0,0 +1,145 @@
+package pgn
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/clfs/aloe/chess"
+	"github.com/clfs/aloe/fen"
+)
+
+func TestWriter_Write(t *testing.T) {
+	g, err := NewReader(strings.NewReader(fischerSpassky)).Read()
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	var b strings.Builder
+	if err := NewWriter(&b).Write(g); err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if got := b.String(); got != fischerSpassky {
+		t.Errorf("got:\n%s\nwant:\n%s", got, fischerSpassky)
+	}
+}
+
+func TestWriter_Write_Variations(t *testing.T) {
+	const in = `{Game comment} 1. e4 $1 (1. d4 {Queen's pawn} d5 (1... Nf6 2. c4) 2. c4) (1. c4)
+1... c5 $5 {Sicilian} 2. Nf3 ({Or} 2. Nc3) 2... d6 *`
+
+	const want = `[Event "?"]
+[Site "?"]
+[Date "????.??.??"]
+[Round "?"]
+[White "?"]
+[Black "?"]
+[Result "*"]
+
+{Game comment} 1. e4 $1 (1. d4 {Queen's pawn} 1... d5 (1... Nf6 2. c4) 2. c4)
+(1. c4) 1... c5 $5 {Sicilian} 2. Nf3 ({Or} 2. Nc3) 2... d6 *
+
+`
+
+	g, err := NewReader(strings.NewReader(in)).Read()
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	var b strings.Builder
+	if err := NewWriter(&b).Write(g); err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if got := b.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriter_Write_WhiteVariation(t *testing.T) {
+	// Black's reply inside a variation that starts with a White move needs no
+	// move number.
+	const in = `1. e4 e5 2. Nf3 Nc6 3. Bb5 (3. Bc4 Bc5 4. c3 (4. O-O {Castles} Nf6)) a6 *`
+
+	const want = "1. e4 e5 2. Nf3 Nc6 3. Bb5 (3. Bc4 Bc5 4. c3 (4. O-O {Castles} 4... Nf6)) 3...\na6 *\n\n"
+
+	g, err := NewReader(strings.NewReader(in)).Read()
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	var b strings.Builder
+	if err := NewWriter(&b).Write(g); err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	_, got, _ := strings.Cut(b.String(), "\n\n")
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriter_Write_NewGame(t *testing.T) {
+	p, err := fen.Decode("4k3/8/8/8/8/8/4P3/4K3 b - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := NewGame(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.SetTag("White", `Some "Engine"`)
+	g.Result = WhiteWins
+
+	n := g.Root
+	for _, s := range []string{"e8d7", "e2e4"} {
+		m, _ := chess.NewMove(s)
+		n = n.AddMove(m)
+	}
+
+	const want = `[Event "?"]
+[Site "?"]
+[Date "????.??.??"]
+[Round "?"]
+[White "Some \"Engine\""]
+[Black "?"]
+[Result "1-0"]
+[SetUp "1"]
+[FEN "4k3/8/8/8/8/8/4P3/4K3 b - - 0 1"]
+
+1... Kd7 2. e4 1-0
+
+`
+
+	var b strings.Builder
+	if err := NewWriter(&b).Write(g); err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if got := b.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// The output reads back to the same game.
+	g2, err := NewReader(strings.NewReader(b.String())).Read()
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	if got, _ := g2.Tag("White"); got != `Some "Engine"` {
+		t.Errorf("got White tag %q", got)
+	}
+	if g2.End().Position != g.End().Position {
+		t.Error("final positions differ")
+	}
+}
+
+func TestWriter_Write_InvalidResult(t *testing.T) {
+	g, _ := NewGame(chess.NewPosition())
+	g.Result = "2-0"
+
+	var b strings.Builder
+	if err := NewWriter(&b).Write(g); err == nil {
+		t.Error("wrote invalid result")
+	}
+}
@@ -0,0 +1,233 @@
+// Package san implements Standard Algebraic Notation (SAN) for moves.
+//
+// [Encode] produces strict SAN, as used in PGN export format. [Decode] also
+// accepts common variants: zeros for castling, a missing capture mark, a
+// missing "=" before a promotion, long algebraic forms like "e2-e4" and
+// trailing check marks, annotations or "e.p." that don't match the position.
+//
+// See [SAN] for more information.
+//
+// [SAN]: https://www.chessprogramming.org/Algebraic_Chess_Notation#Standard_Algebraic_Notation_.28SAN.29
+package san
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/clfs/aloe/chess"
+)
+
+var roleToString = map[chess.Role]string{
+	chess.Knight: "N",
+	chess.Bishop: "B",
+	chess.Rook:   "R",
+	chess.Queen:  "Q",
+	chess.King:   "K",
+}
+
+var stringToRole = map[string]chess.Role{
+	"N": chess.Knight,
+	"B": chess.Bishop,
+	"R": chess.Rook,
+	"Q": chess.Queen,
+	"K": chess.King,
+}
+
+// Encode returns the SAN for a legal move in the provided position.
+func Encode(p chess.Position, m chess.Move) (string, error) {
+	legal := p.LegalMoves()
+	if !contains(legal, m) {
+		return "", fmt.Errorf("illegal move: %v", m)
+	}
+
+	piece, _ := p.Board.At(m.From)
+
+	var b strings.Builder
+
+	switch {
+	case isCastle(piece, m) && m.To.File() > m.From.File():
+		b.WriteString("O-O")
+
+	case isCastle(piece, m):
+		b.WriteString("O-O-O")
+
+	case piece.Role == chess.Pawn:
+		if m.From.File() != m.To.File() {
+			b.WriteString(encodeFile(m.From.File()))
+			b.WriteString("x")
+		}
+
+		b.WriteString(encodeSquare(m.To))
+
+		if r, ok := m.PromotionInfo.Role(); ok {
+			b.WriteString("=")
+			b.WriteString(roleToString[r])
+		}
+
+	default:
+		b.WriteString(roleToString[piece.Role])
+		b.WriteString(disambiguate(p, legal, m, piece.Role))
+
+		if _, ok := p.Board.At(m.To); ok {
+			b.WriteString("x")
+		}
+
+		b.WriteString(encodeSquare(m.To))
+	}
+
+	// Add a check or checkmate suffix.
+	p.Move(m)
+	switch {
+	case p.IsCheckmate():
+		b.WriteString("#")
+	case p.InCheck():
+		b.WriteString("+")
+	}
+
+	return b.String(), nil
+}
+
+// disambiguate returns the shortest prefix that distinguishes m from other
+// legal moves by pieces of the same role to the same square: nothing, the
+// origin file, the origin rank, or both.
+func disambiguate(p chess.Position, legal []chess.Move, m chess.Move, r chess.Role) string {
+	var ambiguous, sameFile, sameRank bool
+
+	for _, other := range legal {
+		if other.To != m.To || other.From == m.From {
+			continue
+		}
+
+		if piece, _ := p.Board.At(other.From); piece.Role != r {
+			continue
+		}
+
+		ambiguous = true
+		sameFile = sameFile || other.From.File() == m.From.File()
+		sameRank = sameRank || other.From.Rank() == m.From.Rank()
+	}
+
+	switch {
+	case !ambiguous:
+		return ""
+	case !sameFile:
+		return encodeFile(m.From.File())
+	case !sameRank:
+		return encodeRank(m.From.Rank())
+	default:
+		return encodeSquare(m.From)
+	}
+}
+
+// Regular expressions for parsing SAN.
+var (
+	rgxCastleShort = regexp.MustCompile(`^[O0o]-?[O0o]$`)
+	rgxCastleLong  = regexp.MustCompile(`^[O0o]-?[O0o]-?[O0o]$`)
+	rgxMove        = regexp.MustCompile(`^([NBRQK])?([a-h])?([1-8])?[x:-]?([a-h][1-8])(?:=?\(?([NBRQ])\)?)?$`)
+)
+
+// Decode returns the legal move in the provided position that matches the SAN.
+func Decode(p chess.Position, san string) (chess.Move, error) {
+	// Check marks and annotations may come before or after "e.p.".
+	s := strings.TrimSpace(san)
+	s = strings.TrimRight(s, "+#!?")
+	s = strings.TrimSuffix(s, "e.p.")
+	s = strings.TrimSpace(s)
+	s = strings.TrimRight(s, "+#!?")
+
+	legal := p.LegalMoves()
+
+	var candidates []chess.Move
+
+	switch {
+	case rgxCastleShort.MatchString(s), rgxCastleLong.MatchString(s):
+		long := rgxCastleLong.MatchString(s)
+		for _, m := range legal {
+			piece, _ := p.Board.At(m.From)
+			if isCastle(piece, m) && (m.To.File() < m.From.File()) == long {
+				candidates = append(candidates, m)
+			}
+		}
+
+	default:
+		match := rgxMove.FindStringSubmatch(s)
+		if match == nil {
+			return chess.Move{}, fmt.Errorf("invalid SAN: %q", san)
+		}
+
+		role := chess.Pawn
+		if match[1] != "" {
+			role = stringToRole[match[1]]
+		}
+
+		to := decodeSquare(match[4])
+
+		promo := chess.NoPromotion
+		if match[5] != "" {
+			promo = chess.PromotionInfo(stringToRole[match[5]])
+		}
+
+		for _, m := range legal {
+			piece, _ := p.Board.At(m.From)
+
+			switch {
+			case piece.Role != role, m.To != to, m.PromotionInfo != promo:
+				continue
+			case match[2] != "" && encodeFile(m.From.File()) != match[2]:
+				continue
+			case match[3] != "" && encodeRank(m.From.Rank()) != match[3]:
+				continue
+			case role == chess.King && isCastle(piece, m):
+				// Castling must be written as such.
+				continue
+			}
+
+			candidates = append(candidates, m)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return chess.Move{}, fmt.Errorf("no legal move matches SAN: %q", san)
+	case 1:
+		return candidates[0], nil
+	default:
+		return chess.Move{}, fmt.Errorf("ambiguous SAN: %q", san)
+	}
+}
+
+// isCastle returns true if the piece making the move is castling.
+func isCastle(piece chess.Piece, m chess.Move) bool {
+	if piece.Role != chess.King {
+		return false
+	}
+	d := int(m.From.File()) - int(m.To.File())
+	return d == 2 || d == -2
+}
+
+func contains(moves []chess.Move, m chess.Move) bool {
+	for _, other := range moves {
+		if m == other {
+			return true
+		}
+	}
+	return false
+}
+
+func encodeFile(f chess.File) string {
+	return string(rune('a' + f))
+}
+
+func encodeRank(r chess.Rank) string {
+	return string(rune('1' + r))
+}
+
+func encodeSquare(s chess.Square) string {
+	return encodeFile(s.File()) + encodeRank(s.Rank())
+}
+
+// decodeSquare returns the square for a string already known to be valid.
+func decodeSquare(s string) chess.Square {
+	return chess.SquareAt(chess.File(s[0]-'a'), chess.Rank(s[1]-'1'))
+}
@@ -0,0 +1,215 @@
+package san
+
+import (
+	"testing"
+
+	"github.com/clfs/aloe/chess"
+	"github.com/clfs/aloe/fen"
+)
+
+var sanTests = []struct {
+	fen  string
+	move string // UCI
+	san  string
+}{
+	{fen.StartingFEN, "e2e4", "e4"},
+	{fen.StartingFEN, "g1f3", "Nf3"},
+	// Disambiguation by file, rank, and both.
+	{"rnbqkb1r/ppp1pppp/5n2/3p4/8/8/PPPPPPPP/RNBQKBNR b KQkq - 0 1", "b8d7", "Nbd7"},
+	{"4k3/8/8/8/8/8/8/R4RK1 w - - 0 1", "a1d1", "Rad1"},
+	{"4k3/R7/8/8/8/8/8/R3K3 w - - 0 1", "a1a4", "R1a4"},
+	{"k7/8/8/8/8/2Q1Q3/8/2Q1K3 w - - 0 1", "c3d2", "Qc3d2"},
+	{"k7/8/8/8/8/2Q1Q3/8/2Q1K3 w - - 0 1", "e3d2", "Qed2"},
+	{"k7/8/8/8/8/2Q1Q3/8/2Q1K3 w - - 0 1", "c1d2", "Q1d2"},
+	// Pieces of a different role don't need disambiguation.
+	{"4k3/8/8/8/8/8/8/R2QK3 w - - 0 1", "d1d4", "Qd4"},
+	// Captures, including en passant.
+	{"rnbqkbnr/ppp1pppp/8/3p4/4P3/8/PPPP1PPP/RNBQKBNR w KQkq - 0 2", "e4d5", "exd5"},
+	{"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3", "e5f6", "exf6"},
+	{"r1bqkbnr/pppppppp/2n5/8/3P4/8/PPP1PPPP/RNBQKBNR b KQkq - 0 2", "c6d4", "Nxd4"},
+	// Castling.
+	{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1g1", "O-O"},
+	{"r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", "e8c8", "O-O-O"},
+	// Promotion.
+	{"4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7b8q", "b8=Q+"},
+	{"2r1k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7c8n", "bxc8=N"},
+	// Check and checkmate.
+	{"rnbqkbnr/pppp1ppp/8/4p3/6P1/5P2/PPPPP2P/RNBQKBNR b KQkq - 0 2", "d8h4", "Qh4#"},
+	{"r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 4 4", "h5f7", "Qxf7#"},
+	{"4k3/8/8/8/8/8/8/4K2R w K - 0 1", "h1h8", "Rh8+"},
+}
+
+func TestEncode(t *testing.T) {
+	for _, tc := range sanTests {
+		p, err := fen.Decode(tc.fen)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.fen, err)
+		}
+
+		m, err := chess.NewMove(tc.move)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.move, err)
+		}
+
+		got, err := Encode(p, m)
+		if err != nil {
+			t.Errorf("%q: %s: error: %v", tc.fen, tc.move, err)
+		}
+		if got != tc.san {
+			t.Errorf("%q: %s: want %q, got %q", tc.fen, tc.move, tc.san, got)
+		}
+	}
+}
+
+func TestEncode_Illegal(t *testing.T) {
+	m, _ := chess.NewMove("e2e5")
+	if _, err := Encode(chess.NewPosition(), m); err == nil {
+		t.Error("encoded illegal move")
+	}
+}
+
+func TestDecode(t *testing.T) {
+	for _, tc := range sanTests {
+		p, err := fen.Decode(tc.fen)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.fen, err)
+		}
+
+		want, err := chess.NewMove(tc.move)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.move, err)
+		}
+
+		got, err := Decode(p, tc.san)
+		if err != nil {
+			t.Errorf("%q: %s: error: %v", tc.fen, tc.san, err)
+		}
+		if got != want {
+			t.Errorf("%q: %s: want %v, got %v", tc.fen, tc.san, want, got)
+		}
+	}
+}
+
+func TestDecode_Lenient(t *testing.T) {
+	cases := []struct {
+		fen  string
+		san  string
+		move string // UCI
+	}{
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "0-0", "e1g1"},
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "0-0-0", "e1c1"},
+		{"rnbqkbnr/ppp1pppp/8/3p4/4P3/8/PPPP1PPP/RNBQKBNR w KQkq - 0 2", "ed5", "e4d5"},
+		{"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3", "exf6 e.p.", "e5f6"},
+		{"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3", "exf6e.p.", "e5f6"},
+		{"8/2k5/8/3pP3/8/8/8/4K3 w - d6 0 2", "exd6 e.p.+", "e5d6"},
+		{"8/2k5/8/3pP3/8/8/8/4K3 w - d6 0 2", "exd6+ e.p.", "e5d6"},
+		{"4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b8Q", "b7b8q"},
+		{"4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b8=Q", "b7b8q"},
+		{"4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b8(Q)", "b7b8q"},
+		{fen.StartingFEN, "e2-e4", "e2e4"},
+		{fen.StartingFEN, "Ng1f3", "g1f3"},
+		{fen.StartingFEN, "e4!?", "e2e4"},
+		{"r1bqkbnr/pppppppp/2n5/8/3P4/8/PPP1PPPP/RNBQKBNR b KQkq - 0 2", "Nd4", "c6d4"},
+	}
+	for _, tc := range cases {
+		p, err := fen.Decode(tc.fen)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.fen, err)
+		}
+
+		want, err := chess.NewMove(tc.move)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.move, err)
+		}
+
+		got, err := Decode(p, tc.san)
+		if err != nil {
+			t.Errorf("%q: %s: error: %v", tc.fen, tc.san, err)
+		}
+		if got != want {
+			t.Errorf("%q: %s: want %v, got %v", tc.fen, tc.san, want, got)
+		}
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	cases := []struct {
+		fen string
+		san string
+	}{
+		{fen.StartingFEN, ""},
+		{fen.StartingFEN, "e5"},
+		{fen.StartingFEN, "Ke2"},
+		{fen.StartingFEN, "O-O"},
+		{fen.StartingFEN, "Xe4"},
+		{fen.StartingFEN, "e2e4e6"},
+		// Ambiguous.
+		{"4k3/8/8/8/8/8/8/R4RK1 w - - 0 1", "Rd1"},
+		// Promotion piece is missing.
+		{"4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b8"},
+	}
+	for _, tc := range cases {
+		p, err := fen.Decode(tc.fen)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.fen, err)
+		}
+
+		if m, err := Decode(p, tc.san); err == nil {
+			t.Errorf("%q: decoded invalid SAN %q as %v", tc.fen, tc.san, m)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	fens := []string{
+		fen.StartingFEN,
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+	}
+	for _, s := range fens {
+		p, err := fen.Decode(s)
+		if err != nil {
+			t.Fatalf("%q: %v", s, err)
+		}
+
+		for _, m := range p.LegalMoves() {
+			text, err := Encode(p, m)
+			if err != nil {
+				t.Errorf("%q: %v: encode error: %v", s, m, err)
+				continue
+			}
+
+			got, err := Decode(p, text)
+			if err != nil {
+				t.Errorf("%q: %q: decode error: %v", s, text, err)
+				continue
+			}
+
+			if got != m {
+				t.Errorf("%q: %q: want %v, got %v", s, text, m, got)
+			}
+		}
+	}
+}
+
+func FuzzDecode(f *testing.F) {
+	for _, tc := range sanTests {
+		f.Add(tc.fen, tc.san)
+	}
+	f.Fuzz(func(t *testing.T, s, text string) {
+		p, err := fen.Decode(s)
+		if err != nil || p.IsValid() != nil {
+			t.Skip()
+		}
+
+		m, err := Decode(p, text)
+		if err != nil {
+			t.Skip()
+		}
+
+		if !p.IsLegalMove(m) {
+			t.Errorf("decoded illegal move %v from %q", m, text)
+		}
+	})
+}
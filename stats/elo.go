@@ -0,0 +1,59 @@
+package stats
+
+import "math"
+
+// Estimate is an Elo difference estimate with a confidence interval. Bounds
+// are infinite when the interval reaches a score of 0 or 1.
+type Estimate struct {
+	Elo          float64
+	Lower, Upper float64
+}
+
+// Elo estimates the Elo difference between the engines, with a two-sided
+// confidence interval at the given confidence level, like 0.95. It returns NaN
+// values if there are no results.
+func Elo(r Results, confidence float64) Estimate {
+	n, mean, variance := r.moments()
+	if n == 0 {
+		nan := math.NaN()
+		return Estimate{nan, nan, nan}
+	}
+
+	margin := zScore(confidence) * math.Sqrt(variance/float64(n))
+
+	return Estimate{
+		Elo:   EloFromScore(mean),
+		Lower: EloFromScore(mean - margin),
+		Upper: EloFromScore(mean + margin),
+	}
+}
+
+// LOS returns the likelihood of superiority: the probability that the first
+// engine is stronger than the second. It's 0.5 if there are no results.
+func LOS(r Results) float64 {
+	n, mean, variance := r.moments()
+
+	d := mean - 0.5
+	switch {
+	case n == 0 || d == 0:
+		return 0.5
+	case variance == 0 && d > 0:
+		return 1
+	case variance == 0:
+		return 0
+	}
+
+	return normalCDF(d / math.Sqrt(variance/float64(n)))
+}
+
+// zScore returns the number of standard deviations around the mean of a
+// normal distribution that cover the given probability.
+func zScore(confidence float64) float64 {
+	return math.Sqrt2 * math.Erfinv(confidence)
+}
+
+// normalCDF is the cumulative distribution function of the standard normal
+// distribution.
+func normalCDF(x float64) float64 {
+	return (1 + math.Erf(x/math.Sqrt2)) / 2
+}
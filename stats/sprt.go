@@ -0,0 +1,91 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+)
+
+// Decision is the outcome of a sequential probability ratio test so far.
+type Decision int
+
+// Decision constants.
+const (
+	Continue Decision = iota // Neither hypothesis is accepted yet.
+	AcceptH0                 // The Elo difference is at most Elo0.
+	AcceptH1                 // The Elo difference is at least Elo1.
+)
+
+func (d Decision) String() string {
+	switch d {
+	case Continue:
+		return "continue"
+	case AcceptH0:
+		return "H0 accepted"
+	case AcceptH1:
+		return "H1 accepted"
+	}
+	return fmt.Sprintf("Decision(%d)", int(d))
+}
+
+// SPRT is a sequential probability ratio test of the hypothesis H0 that the
+// Elo difference between the engines is Elo0 against H1 that it's Elo1. The
+// test may be checked after every result, and the match stopped once it
+// accepts either hypothesis.
+//
+// A typical test of a patch uses Elo0 = 0, Elo1 = 5 and Alpha = Beta = 0.05.
+//
+// The log-likelihood ratio is that of the generalized SPRT, which models the
+// mean score as normally distributed with the variance seen in the results.
+// Elo differences are logistic, as returned by [EloFromScore].
+type SPRT struct {
+	Elo0, Elo1  float64
+	Alpha, Beta float64 // Probabilities of false positives and false negatives.
+}
+
+// Validate returns an error if the test is invalid.
+func (s SPRT) Validate() error {
+	switch {
+	case !(s.Elo0 < s.Elo1):
+		return fmt.Errorf("invalid SPRT: elo0 %v not below elo1 %v", s.Elo0, s.Elo1)
+	case !(s.Alpha > 0 && s.Alpha < 1):
+		return fmt.Errorf("invalid SPRT: alpha %v not between 0 and 1", s.Alpha)
+	case !(s.Beta > 0 && s.Beta < 1):
+		return fmt.Errorf("invalid SPRT: beta %v not between 0 and 1", s.Beta)
+	case s.Alpha+s.Beta >= 1:
+		return fmt.Errorf("invalid SPRT: alpha %v plus beta %v is at least 1", s.Alpha, s.Beta)
+	}
+	return nil
+}
+
+// Bounds returns the log-likelihood ratios at which H0 and H1 are accepted.
+func (s SPRT) Bounds() (lower, upper float64) {
+	lower = math.Log(s.Beta / (1 - s.Alpha))
+	upper = math.Log((1 - s.Beta) / s.Alpha)
+	return lower, upper
+}
+
+// LLR returns the log-likelihood ratio of H1 to H0 given some results. It's 0
+// until the results have some variance.
+func (s SPRT) LLR(r Results) float64 {
+	n, mean, variance := r.moments()
+	if n == 0 || variance == 0 {
+		return 0
+	}
+
+	s0, s1 := ScoreFromElo(s.Elo0), ScoreFromElo(s.Elo1)
+	return float64(n) * (s1 - s0) * (2*mean - s0 - s1) / (2 * variance)
+}
+
+// Test returns the decision given some results.
+func (s SPRT) Test(r Results) Decision {
+	llr := s.LLR(r)
+	lower, upper := s.Bounds()
+
+	switch {
+	case llr >= upper:
+		return AcceptH1
+	case llr <= lower:
+		return AcceptH0
+	}
+	return Continue
+}
@@ -0,0 +1,71 @@
+package stats
+
+import "testing"
+
+var patchTest = SPRT{Elo0: 0, Elo1: 5, Alpha: 0.05, Beta: 0.05}
+
+func TestSPRT_Validate(t *testing.T) {
+	if err := patchTest.Validate(); err != nil {
+		t.Errorf("error: %v", err)
+	}
+
+	invalid := []SPRT{
+		{Elo0: 5, Elo1: 0, Alpha: 0.05, Beta: 0.05},
+		{Elo0: 0, Elo1: 0, Alpha: 0.05, Beta: 0.05},
+		{Elo0: 0, Elo1: 5, Alpha: 0, Beta: 0.05},
+		{Elo0: 0, Elo1: 5, Alpha: 0.05, Beta: 1},
+		{Elo0: 0, Elo1: 5, Alpha: 0.5, Beta: 0.5},
+	}
+	for _, s := range invalid {
+		if err := s.Validate(); err == nil {
+			t.Errorf("%+v: no error", s)
+		}
+	}
+}
+
+func TestSPRT_Bounds(t *testing.T) {
+	lower, upper := patchTest.Bounds()
+	if !approx(lower, -2.944) || !approx(upper, 2.944) {
+		t.Errorf("got bounds %v, %v", lower, upper)
+	}
+}
+
+func TestSPRT_LLR(t *testing.T) {
+	// Expected values are from an independent implementation.
+	cases := []struct {
+		results Results
+		want    float64
+	}{
+		{Trinomial{Wins: 60, Draws: 20, Losses: 20}, 0.883},
+		{Pentanomial{2, 20, 50, 24, 4}, 0.280},
+		{Pentanomial{10, 30, 40, 15, 5}, -0.771},
+		{Pentanomial{}, 0},
+		{Pentanomial{0, 0, 7}, 0},
+	}
+
+	for _, tc := range cases {
+		if got := patchTest.LLR(tc.results); !approx(got, tc.want) {
+			t.Errorf("%+v: got %v, want %v", tc.results, got, tc.want)
+		}
+	}
+}
+
+func TestSPRT_Test(t *testing.T) {
+	cases := []struct {
+		results Results
+		want    Decision
+	}{
+		{Pentanomial{}, Continue},
+		{Pentanomial{2, 20, 50, 24, 4}, Continue},
+		{Pentanomial{40, 400, 1000, 480, 80}, AcceptH1},
+		{Pentanomial{80, 480, 1000, 400, 40}, AcceptH0},
+		{Pentanomial{60, 440, 1000, 440, 60}, Continue},
+		{Pentanomial{240, 1760, 4000, 1760, 240}, AcceptH0},
+	}
+
+	for _, tc := range cases {
+		if got := patchTest.Test(tc.results); got != tc.want {
+			t.Errorf("%v: got %v, want %v", tc.results, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// Package stats implements statistics for engine matches: Elo difference
+// estimates with confidence intervals, the likelihood of superiority (LOS),
+// and a sequential probability ratio test (SPRT) to stop a match as soon as
+// one engine is known to be stronger or not.
+//
+// Results are tallied from the first engine's point of view, either per game
+// with [Trinomial] or per game pair with [Pentanomial]. A game pair is an
+// opening played twice, with the engines swapping colors. Pairs are less noisy
+// than single games, since the bias of an unbalanced opening cancels out, so
+// pentanomial results give tighter estimates from the same games.
+//
+// All statistics use the normal approximation, which is accurate once a match
+// has more than a few dozen games.
+//
+// See [Match Statistics] for more information.
+//
+// [Match Statistics]: https://www.chessprogramming.org/Match_Statistics
+package stats
+
+import "math"
+
+// Results are match results from the first engine's point of view.
+type Results interface {
+	// moments returns the number of samples, and the mean and variance of
+	// the score per sample, scaled to between 0 and 1.
+	moments() (n int, mean, variance float64)
+}
+
+// Result is the result of a game for the first engine.
+type Result int
+
+// Result constants. A Result is also the number of half points scored.
+const (
+	Loss Result = iota
+	Draw
+	Win
+)
+
+// Trinomial counts games by result.
+type Trinomial struct {
+	Wins, Draws, Losses int
+}
+
+// Add adds a game.
+func (t *Trinomial) Add(r Result) {
+	switch r {
+	case Loss:
+		t.Losses++
+	case Draw:
+		t.Draws++
+	case Win:
+		t.Wins++
+	}
+}
+
+// Games returns the number of games.
+func (t Trinomial) Games() int {
+	return t.Wins + t.Draws + t.Losses
+}
+
+func (t Trinomial) moments() (int, float64, float64) {
+	return moments([]int{t.Losses, t.Draws, t.Wins})
+}
+
+// Pentanomial counts game pairs by the points the first engine scored in
+// them: Pentanomial[i] is the number of pairs in which it scored i/2 points.
+type Pentanomial [5]int
+
+// Add adds a game pair. The results must be valid Result constants. If not,
+// behavior is undefined.
+func (p *Pentanomial) Add(first, second Result) {
+	p[first+second]++
+}
+
+// Pairs returns the number of game pairs.
+func (p Pentanomial) Pairs() int {
+	n := 0
+	for _, c := range p {
+		n += c
+	}
+	return n
+}
+
+func (p Pentanomial) moments() (int, float64, float64) {
+	return moments(p[:])
+}
+
+// moments returns the number of samples, and the mean and variance of their
+// scores, given counts of samples scoring 0 to 1 in equal steps.
+func moments(counts []int) (n int, mean, variance float64) {
+	for _, c := range counts {
+		n += c
+	}
+	if n == 0 {
+		return 0, 0, 0
+	}
+
+	step := 1 / float64(len(counts)-1)
+
+	for i, c := range counts {
+		mean += float64(i) * step * float64(c)
+	}
+	mean /= float64(n)
+
+	for i, c := range counts {
+		d := float64(i)*step - mean
+		variance += d * d * float64(c)
+	}
+	variance /= float64(n)
+
+	return n, mean, variance
+}
+
+// EloFromScore returns the Elo difference at which a player is expected to
+// score s points per game, for s between 0 and 1. It's -Inf for s <= 0 and
+// +Inf for s >= 1.
+func EloFromScore(s float64) float64 {
+	switch {
+	case s <= 0:
+		return math.Inf(-1)
+	case s >= 1:
+		return math.Inf(1)
+	}
+	return 400 * math.Log10(s/(1-s))
+}
+
+// ScoreFromElo returns the expected points per game of a player that is elo
+// points stronger. It's the inverse of [EloFromScore].
+func ScoreFromElo(elo float64) float64 {
+	return 1 / (1 + math.Pow(10, -elo/400))
+}
@@ -0,0 +1,124 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+// approx reports whether a and b are equal to within 1e-3.
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-3
+}
+
+func TestTrinomial_Add(t *testing.T) {
+	var tri Trinomial
+	for _, r := range []Result{Win, Win, Draw, Loss} {
+		tri.Add(r)
+	}
+
+	if want := (Trinomial{Wins: 2, Draws: 1, Losses: 1}); tri != want {
+		t.Errorf("got %+v, want %+v", tri, want)
+	}
+	if got := tri.Games(); got != 4 {
+		t.Errorf("got %d games, want 4", got)
+	}
+}
+
+func TestPentanomial_Add(t *testing.T) {
+	var p Pentanomial
+	p.Add(Loss, Loss)
+	p.Add(Win, Loss)
+	p.Add(Draw, Draw)
+	p.Add(Win, Draw)
+
+	if want := (Pentanomial{1, 0, 2, 1, 0}); p != want {
+		t.Errorf("got %v, want %v", p, want)
+	}
+	if got := p.Pairs(); got != 4 {
+		t.Errorf("got %d pairs, want 4", got)
+	}
+}
+
+func TestEloFromScore(t *testing.T) {
+	cases := []struct {
+		score, elo float64
+	}{
+		{0.5, 0},
+		{0.75, 190.849},
+		{0.25, -190.849},
+		{0, math.Inf(-1)},
+		{1, math.Inf(1)},
+	}
+
+	for _, tc := range cases {
+		got := EloFromScore(tc.score)
+		if got != tc.elo && !approx(got, tc.elo) {
+			t.Errorf("EloFromScore(%v): got %v, want %v", tc.score, got, tc.elo)
+		}
+		if s := ScoreFromElo(got); s != tc.score && !approx(s, tc.score) {
+			t.Errorf("ScoreFromElo(%v): got %v, want %v", got, s, tc.score)
+		}
+	}
+}
+
+// Expected values are from an independent implementation.
+var eloTests = []struct {
+	name         string
+	results      Results
+	elo          float64
+	lower, upper float64
+	los          float64
+}{
+	{"trinomial", Trinomial{Wins: 60, Draws: 20, Losses: 20}, 147.191, 86.225, 218.252, 1.000},
+	{"pentanomial", Pentanomial{2, 20, 50, 24, 4}, 13.905, -14.055, 42.046, 0.835},
+	{"pentanomial negative", Pentanomial{10, 30, 40, 15, 5}, -43.658, -78.577, -9.597, 0.006},
+}
+
+func TestElo(t *testing.T) {
+	for _, tc := range eloTests {
+		got := Elo(tc.results, 0.95)
+		if !approx(got.Elo, tc.elo) || !approx(got.Lower, tc.lower) || !approx(got.Upper, tc.upper) {
+			t.Errorf("%s: got %+v, want %v [%v, %v]", tc.name, got, tc.elo, tc.lower, tc.upper)
+		}
+	}
+}
+
+func TestElo_Degenerate(t *testing.T) {
+	if got := Elo(Trinomial{}, 0.95); !math.IsNaN(got.Elo) {
+		t.Errorf("no games: got %+v", got)
+	}
+
+	got := Elo(Trinomial{Wins: 3}, 0.95)
+	if !math.IsInf(got.Elo, 1) || !math.IsInf(got.Lower, 1) {
+		t.Errorf("all wins: got %+v", got)
+	}
+
+	got = Elo(Trinomial{Draws: 3}, 0.95)
+	if got.Elo != 0 || got.Lower != 0 || got.Upper != 0 {
+		t.Errorf("all draws: got %+v", got)
+	}
+}
+
+func TestLOS(t *testing.T) {
+	for _, tc := range eloTests {
+		if got := LOS(tc.results); !approx(got, tc.los) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.los)
+		}
+	}
+
+	cases := []struct {
+		results Results
+		want    float64
+	}{
+		{Trinomial{}, 0.5},
+		{Trinomial{Draws: 3}, 0.5},
+		{Trinomial{Wins: 3}, 1},
+		{Pentanomial{3}, 0},
+		{Trinomial{Wins: 5, Losses: 5}, 0.5},
+	}
+	for _, tc := range cases {
+		if got := LOS(tc.results); got != tc.want {
+			t.Errorf("%+v: got %v, want %v", tc.results, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,344 @@
+// Package client drives UCI chess engines, such as reference engines to test
+// against.
+//
+// A [Client] talks to an engine over any connection, or over the standard
+// input and output of a subprocess started with [Start]:
+//
+//	c, err := client.Start(exec.Command("stockfish"))
+//	if err != nil {
+//		// ...
+//	}
+//	defer c.Close()
+//
+//	if err := c.Handshake(ctx); err != nil {
+//		// ...
+//	}
+//	c.SetPosition(&uci.Position{Start: chess.NewPosition()})
+//	best, err := c.Go(ctx, &uci.Go{Depth: 10}, nil)
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	ucienc "github.com/clfs/aloe/encoding/uci"
+	"github.com/clfs/aloe/uci"
+)
+
+// CloseTimeout is how long Close waits for a subprocess to exit after "quit"
+// before killing it.
+var CloseTimeout = 5 * time.Second
+
+// ErrClosed is returned when the client is used after Close.
+var ErrClosed = errors.New("client closed")
+
+// Client is a connection to a UCI engine.
+//
+// Only Stop and PonderHit may be called while another method is running.
+type Client struct {
+	mu  sync.Mutex // Guards writes to enc.
+	enc *ucienc.Encoder
+
+	responses chan uci.Response // Closed when the engine's output ends.
+	err       error             // Why responses was closed. Read only after it is.
+	ended     chan struct{}     // Closed once the engine's output is read to the end.
+	done      chan struct{}     // Closed by Close.
+	closeOnce sync.Once
+	closeErr  error
+	release   func() error // Releases the connection, after "quit".
+
+	searching bool // The bestmove of an abandoned search is still due.
+
+	// Set by Handshake.
+	Name    string
+	Author  string
+	Options []uci.Option
+}
+
+// New returns a client for an engine that reads requests from rw and writes
+// responses to it. If rw is an [io.Closer], Close closes it.
+func New(rw io.ReadWriter) *Client {
+	release := func() error { return nil }
+	if c, ok := rw.(io.Closer); ok {
+		release = c.Close
+	}
+	return newClient(rw, rw, release)
+}
+
+// Start starts cmd and returns a client for it, connected to its standard
+// input and output. The caller may set up cmd's other fields, such as Dir and
+// Stderr, but not Stdin or Stdout.
+func Start(cmd *exec.Cmd) (*Client, error) {
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	c := newClient(stdout, stdin, nil)
+	c.release = func() error {
+		stdin.Close()
+
+		// Wait closes stdout, so it must not run until all of the output is
+		// read. Killing the process ends the output too.
+		exited := make(chan error, 1)
+		go func() {
+			<-c.ended
+			exited <- cmd.Wait()
+		}()
+
+		select {
+		case err := <-exited:
+			return err
+		case <-time.After(CloseTimeout):
+			cmd.Process.Kill()
+			return <-exited
+		}
+	}
+
+	return c, nil
+}
+
+func newClient(r io.Reader, w io.Writer, release func() error) *Client {
+	c := &Client{
+		enc:       ucienc.NewEncoder(w),
+		responses: make(chan uci.Response, 64),
+		ended:     make(chan struct{}),
+		done:      make(chan struct{}),
+		release:   release,
+	}
+	go c.read(ucienc.NewDecoder(r))
+	return c
+}
+
+// read sends the engine's responses to c.responses until its output ends.
+// After Close, it discards them instead.
+func (c *Client) read(dec *ucienc.Decoder) {
+	defer close(c.ended)
+	defer close(c.responses)
+
+	for {
+		resp, err := dec.Response()
+
+		// Engines may print anything, so lines that aren't responses are
+		// skipped.
+		var syntax *ucienc.SyntaxError
+		if errors.As(err, &syntax) {
+			continue
+		}
+		if err != nil {
+			c.err = err
+			return
+		}
+
+		select {
+		case c.responses <- resp:
+		case <-c.done:
+		}
+	}
+}
+
+// send sends a request to the engine.
+func (c *Client) send(req uci.Request) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.enc.Encode(req)
+}
+
+// next returns the next response from the engine.
+func (c *Client) next(ctx context.Context) (uci.Response, error) {
+	select {
+	case resp, ok := <-c.responses:
+		if !ok {
+			if c.err == nil {
+				return nil, ErrClosed
+			}
+			if errors.Is(c.err, io.EOF) {
+				return nil, fmt.Errorf("engine output ended: %w", io.ErrUnexpectedEOF)
+			}
+			return nil, c.err
+		}
+		return resp, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// drain waits for the bestmove of an abandoned search, if any.
+func (c *Client) drain(ctx context.Context) error {
+	for c.searching {
+		resp, err := c.next(ctx)
+		if err != nil {
+			return err
+		}
+		if _, ok := resp.(*uci.BestMove); ok {
+			c.searching = false
+		}
+	}
+	return nil
+}
+
+// Handshake sends "uci" and waits for "uciok", recording the engine's name,
+// author and options.
+func (c *Client) Handshake(ctx context.Context) error {
+	if err := c.send(&uci.UCI{}); err != nil {
+		return err
+	}
+
+	c.Name, c.Author, c.Options = "", "", nil
+
+	for {
+		resp, err := c.next(ctx)
+		if err != nil {
+			return err
+		}
+
+		switch resp := resp.(type) {
+		case *uci.ID:
+			if resp.Name != "" {
+				c.Name = resp.Name
+			}
+			if resp.Author != "" {
+				c.Author = resp.Author
+			}
+		case *uci.Option:
+			c.Options = append(c.Options, *resp)
+		case *uci.UCIOk:
+			return nil
+		}
+	}
+}
+
+// Option returns the option with the given name, which is case-insensitive.
+// It reports false if the engine didn't declare the option in Handshake.
+func (c *Client) Option(name string) (uci.Option, bool) {
+	for _, opt := range c.Options {
+		if strings.EqualFold(opt.Name, name) {
+			return opt, true
+		}
+	}
+	return uci.Option{}, false
+}
+
+// SetOption sets an option. The value is empty for buttons. After Handshake,
+// it returns an error for options the engine didn't declare.
+func (c *Client) SetOption(name, value string) error {
+	if c.Options != nil {
+		if _, ok := c.Option(name); !ok {
+			return fmt.Errorf("unknown option %q", name)
+		}
+	}
+	return c.send(&uci.SetOption{Name: name, Value: value})
+}
+
+// IsReady sends "isready" and waits for "readyok".
+func (c *Client) IsReady(ctx context.Context) error {
+	if err := c.drain(ctx); err != nil {
+		return err
+	}
+
+	if err := c.send(&uci.IsReady{}); err != nil {
+		return err
+	}
+
+	for {
+		resp, err := c.next(ctx)
+		if err != nil {
+			return err
+		}
+		if _, ok := resp.(*uci.ReadyOk); ok {
+			return nil
+		}
+	}
+}
+
+// NewGame tells the engine that the next search is from a different game, and
+// waits for it to be ready.
+func (c *Client) NewGame(ctx context.Context) error {
+	if err := c.send(&uci.UCINewGame{}); err != nil {
+		return err
+	}
+	return c.IsReady(ctx)
+}
+
+// SetPosition sets the position to search.
+func (c *Client) SetPosition(p *uci.Position) error {
+	return c.send(p)
+}
+
+// Go starts a search and waits for its best move. Unless infos is nil, each
+// info message of the search is sent on it.
+//
+// If ctx is done first, Go tells the engine to stop and returns ctx.Err()
+// without waiting. The late best move is discarded before the next search.
+func (c *Client) Go(ctx context.Context, g *uci.Go, infos chan<- *uci.Info) (*uci.BestMove, error) {
+	if err := c.drain(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := c.send(g); err != nil {
+		return nil, err
+	}
+	c.searching = true
+
+	for {
+		resp, err := c.next(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				c.Stop()
+			}
+			return nil, err
+		}
+
+		switch resp := resp.(type) {
+		case *uci.Info:
+			if infos == nil {
+				continue
+			}
+			select {
+			case infos <- resp:
+			case <-ctx.Done():
+				c.Stop()
+				return nil, ctx.Err()
+			}
+		case *uci.BestMove:
+			c.searching = false
+			return resp, nil
+		}
+	}
+}
+
+// Stop tells the engine to end the current search. Go then returns the best
+// move found so far.
+func (c *Client) Stop() error {
+	return c.send(&uci.Stop{})
+}
+
+// PonderHit tells the engine that the opponent played the move it is
+// pondering on.
+func (c *Client) PonderHit() error {
+	return c.send(&uci.PonderHit{})
+}
+
+// Close sends "quit" and releases the connection. A subprocess started with
+// [Start] is killed if it doesn't exit within CloseTimeout.
+func (c *Client) Close() error {
+	c.closeOnce.Do(func() {
+		c.send(&uci.Quit{})
+		close(c.done)
+		c.closeErr = c.release()
+	})
+	return c.closeErr
+}
@@ -0,0 +1,351 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/clfs/aloe/chess"
+	ucienc "github.com/clfs/aloe/encoding/uci"
+	"github.com/clfs/aloe/fen"
+	"github.com/clfs/aloe/uci"
+)
+
+// fakeEngineEnv selects the behavior of the test binary when it runs as a
+// subprocess. See TestMain.
+const fakeEngineEnv = "ALOE_CLIENT_FAKE_ENGINE"
+
+func TestMain(m *testing.M) {
+	switch os.Getenv(fakeEngineEnv) {
+	case "":
+		os.Exit(m.Run())
+	case "chatty":
+		// Print more than a pipe holds after the input ends, like an
+		// engine that logs a lot on exit.
+		io.Copy(io.Discard, os.Stdin)
+		for i := 0; i < 10000; i++ {
+			io.WriteString(os.Stdout, "info string exiting\n")
+		}
+		os.Exit(0)
+	case "hang":
+		// Never read anything, including "quit". An empty select would
+		// crash as a deadlock.
+		time.Sleep(time.Hour)
+		os.Exit(1)
+	default:
+		fakeEngine(os.Stdin, os.Stdout)
+		os.Exit(0)
+	}
+}
+
+// fakeEngine is a stand-in UCI engine. It "searches" by reporting one info
+// line per depth and playing the first legal move. Infinite searches wait for
+// "stop". It returns on "quit", at the end of its input, or on "go mate 1",
+// which simulates a crash.
+func fakeEngine(r io.Reader, w io.Writer) {
+	dec, enc := ucienc.NewDecoder(r), ucienc.NewEncoder(w)
+
+	pos := chess.NewPosition()
+	searching := false
+
+	bestMove := func() *uci.BestMove {
+		moves := pos.LegalMoves()
+		if len(moves) == 0 {
+			return &uci.BestMove{}
+		}
+		return &uci.BestMove{Move: moves[0]}
+	}
+
+	for {
+		req, err := dec.Request()
+		var syntax *ucienc.SyntaxError
+		if errors.As(err, &syntax) {
+			continue
+		} else if err != nil {
+			return
+		}
+
+		switch req := req.(type) {
+		case *uci.UCI:
+			io.WriteString(w, "Fake engine by the Aloe authors\n")
+			enc.Encode(&uci.ID{Name: "Fake"})
+			enc.Encode(&uci.ID{Author: "the Aloe authors"})
+			enc.Encode(&uci.Option{Name: "Hash", Type: uci.OptionTypeSpin, Default: "16", Min: 1, Max: 1024})
+			enc.Encode(&uci.Option{Name: "Playing Style", Type: uci.OptionTypeCombo, Default: "Normal", Vars: []string{"Solid", "Normal"}})
+			enc.Encode(&uci.UCIOk{})
+		case *uci.IsReady:
+			enc.Encode(&uci.ReadyOk{})
+		case *uci.Position:
+			pos = req.Start
+			for _, m := range req.Moves {
+				pos.Move(m)
+			}
+		case *uci.Go:
+			if req.Mate == 1 {
+				return
+			}
+			best := bestMove()
+			if req.Infinite {
+				enc.Encode(&uci.Info{Depth: 1, PV: []chess.Move{best.Move}})
+				searching = true
+				continue
+			}
+			for d := 1; d <= req.Depth; d++ {
+				enc.Encode(&uci.Info{Depth: d, Score: 10 * d, ScoreType: uci.ScoreTypeCentipawn, PV: []chess.Move{best.Move}})
+			}
+			enc.Encode(best)
+		case *uci.Stop:
+			if searching {
+				searching = false
+				enc.Encode(bestMove())
+			}
+		case *uci.Quit:
+			return
+		}
+	}
+}
+
+// newTestClient returns a client connected to a fake engine over pipes.
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+
+	engineIn, clientOut := io.Pipe()
+	clientIn, engineOut := io.Pipe()
+
+	go func() {
+		fakeEngine(engineIn, engineOut)
+		engineIn.Close()
+		engineOut.Close()
+	}()
+
+	c := New(struct {
+		io.Reader
+		io.Writer
+	}{clientIn, clientOut})
+	t.Cleanup(func() { c.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.Handshake(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+// testContext returns a context that fails slow tests rather than hanging.
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestClient_Handshake(t *testing.T) {
+	c := newTestClient(t)
+
+	if c.Name != "Fake" || c.Author != "the Aloe authors" {
+		t.Errorf("want Fake by the Aloe authors, got %q by %q", c.Name, c.Author)
+	}
+
+	opt, ok := c.Option("playing style")
+	if !ok {
+		t.Fatalf("option not found in %+v", c.Options)
+	}
+	if opt.Type != uci.OptionTypeCombo || len(opt.Vars) != 2 {
+		t.Errorf("got %+v", opt)
+	}
+
+	if err := c.SetOption("Hash", "32"); err != nil {
+		t.Error(err)
+	}
+	if err := c.SetOption("Hash Size", "32"); err == nil {
+		t.Error("want error for an unknown option")
+	}
+
+	if err := c.NewGame(testContext(t)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClient_Go(t *testing.T) {
+	c := newTestClient(t)
+	ctx := testContext(t)
+
+	start, _ := fen.Decode("k7/8/1K6/8/8/8/8/7R w - - 0 1")
+	if err := c.SetPosition(&uci.Position{Start: start}); err != nil {
+		t.Fatal(err)
+	}
+
+	infos := make(chan *uci.Info, 10)
+	best, err := c.Go(ctx, &uci.Go{Depth: 3}, infos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	close(infos)
+
+	want := start.LegalMoves()[0]
+	if best.Move != want {
+		t.Errorf("want bestmove %s, got %s", want.LAN(), best.Move.LAN())
+	}
+
+	depth := 0
+	for info := range infos {
+		depth++
+		if info.Depth != depth || info.Score != 10*depth || len(info.PV) != 1 {
+			t.Errorf("unexpected info %+v", info)
+		}
+	}
+	if depth != 3 {
+		t.Errorf("want 3 infos, got %d", depth)
+	}
+}
+
+func TestClient_Stop(t *testing.T) {
+	c := newTestClient(t)
+
+	type result struct {
+		best *uci.BestMove
+		err  error
+	}
+	results := make(chan result)
+	infos := make(chan *uci.Info)
+
+	go func() {
+		best, err := c.Go(testContext(t), &uci.Go{Infinite: true}, infos)
+		results <- result{best, err}
+	}()
+
+	// Stop once the search is underway.
+	<-infos
+	if err := c.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := <-results
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+	start := chess.NewPosition()
+	if want := start.LegalMoves()[0]; r.best.Move != want {
+		t.Errorf("want bestmove %s, got %s", want.LAN(), r.best.Move.LAN())
+	}
+}
+
+func TestClient_GoCanceled(t *testing.T) {
+	c := newTestClient(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := c.Go(ctx, &uci.Go{Infinite: true}, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want context.DeadlineExceeded, got %v", err)
+	}
+
+	// The abandoned search's bestmove must not be taken for the next one.
+	start, _ := fen.Decode("k7/8/1K6/8/8/8/8/7R w - - 0 1")
+	if err := c.SetPosition(&uci.Position{Start: start}); err != nil {
+		t.Fatal(err)
+	}
+
+	best, err := c.Go(testContext(t), &uci.Go{Depth: 1}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := start.LegalMoves()[0]; best.Move != want {
+		t.Errorf("want bestmove %s, got %s", want.LAN(), best.Move.LAN())
+	}
+}
+
+func TestClient_Closed(t *testing.T) {
+	c := newTestClient(t)
+	c.Close()
+
+	if err := c.IsReady(testContext(t)); err == nil {
+		t.Error("want error after Close")
+	}
+}
+
+func TestClient_EngineExits(t *testing.T) {
+	c := newTestClient(t)
+
+	if _, err := c.Go(testContext(t), &uci.Go{Mate: 1}, nil); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("want io.ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+// fakeEngineCmd returns a command that runs the test binary as a fake engine.
+func fakeEngineCmd(mode string) *exec.Cmd {
+	cmd := exec.Command(os.Args[0])
+	cmd.Env = append(os.Environ(), fakeEngineEnv+"="+mode)
+	return cmd
+}
+
+func TestStart(t *testing.T) {
+	c, err := Start(fakeEngineCmd("normal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := testContext(t)
+
+	if err := c.Handshake(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.IsReady(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.SetPosition(&uci.Position{Start: chess.NewPosition()}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Go(ctx, &uci.Go{Depth: 2}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Errorf("want a clean exit, got %v", err)
+	}
+}
+
+func TestStart_Kill(t *testing.T) {
+	defer func(d time.Duration) { CloseTimeout = d }(CloseTimeout)
+	CloseTimeout = 100 * time.Millisecond
+
+	c, err := Start(fakeEngineCmd("hang"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := c.Handshake(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want context.DeadlineExceeded, got %v", err)
+	}
+
+	closed := make(chan error)
+	go func() { closed <- c.Close() }()
+
+	select {
+	case err := <-closed:
+		if err == nil {
+			t.Error("want an error for a killed engine")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close didn't kill the engine")
+	}
+}
+
+func TestStart_OutputAfterQuit(t *testing.T) {
+	c, err := Start(fakeEngineCmd("chatty"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Errorf("want a clean exit, got %v", err)
+	}
+}
@@ -0,0 +1,1180 @@
+package uci
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/clfs/aloe/chess"
+	"github.com/clfs/aloe/fen"
+)
+
+// parseMove parses a move in long algebraic notation. The null move "0000" is
+// the zero [chess.Move].
+func parseMove(s string) (chess.Move, error) {
+	if s == "0000" {
+		return chess.Move{}, nil
+	}
+	return chess.NewMove(s)
+}
+
+// parseMoves parses a list of moves in long algebraic notation.
+func parseMoves(ss []string) ([]chess.Move, error) {
+	if len(ss) == 0 {
+		return nil, nil
+	}
+	moves := make([]chess.Move, len(ss))
+	for i, s := range ss {
+		m, err := parseMove(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s, err)
+		}
+		moves[i] = m
+	}
+	return moves, nil
+}
+
+// appendMoves appends moves to text in long algebraic notation, separated by
+// spaces.
+func appendMoves(text []byte, moves []chess.Move) []byte {
+	for i, m := range moves {
+		if i > 0 {
+			text = append(text, ' ')
+		}
+		text = append(text, m.LAN()...)
+	}
+	return text
+}
+
+// BestMove represents the "bestmove" message.
+type BestMove struct {
+	Move   chess.Move // The null move if the engine has no move to play.
+	Ponder chess.Move // The move to ponder on. Ignored if it's the null move.
+}
+
+func (*BestMove) response() {}
+
+func (b *BestMove) MarshalText() ([]byte, error) {
+	text := fmt.Appendf(nil, "bestmove %s", b.Move.LAN())
+
+	if b.Ponder != (chess.Move{}) {
+		text = fmt.Appendf(text, " ponder %s", b.Ponder.LAN())
+	}
+
+	return text, nil
+}
+
+func (b *BestMove) UnmarshalText(text []byte) error {
+	fields := strings.Fields(string(text))
+
+	if len(fields) == 0 {
+		return fmt.Errorf("invalid bestmove")
+	}
+
+	if fields[0] != "bestmove" {
+		return fmt.Errorf("invalid bestmove")
+	}
+
+	if len(fields) < 2 {
+		return fmt.Errorf("invalid bestmove")
+	}
+
+	*b = BestMove{}
+
+	// Some engines send "(none)" when they have no move to play.
+	if fields[1] != "(none)" {
+		m, err := parseMove(fields[1])
+		if err != nil {
+			return fmt.Errorf("invalid bestmove: %w", err)
+		}
+		b.Move = m
+	}
+
+	if len(fields) > 2 {
+		if fields[2] != "ponder" {
+			return fmt.Errorf("invalid bestmove")
+		}
+
+		if len(fields) != 4 {
+			return fmt.Errorf("invalid bestmove")
+		}
+
+		m, err := parseMove(fields[3])
+		if err != nil {
+			return fmt.Errorf("invalid bestmove: %w", err)
+		}
+		b.Ponder = m
+	}
+
+	return nil
+}
+
+// Statuses used in [CopyProtection] and [Registration].
+const (
+	StatusChecking = "checking"
+	StatusOK       = "ok"
+	StatusError    = "error"
+)
+
+// marshalStatus returns the encoding of a message with a status.
+func marshalStatus(name, status string) ([]byte, error) {
+	switch status {
+	case StatusChecking, StatusOK, StatusError:
+		return fmt.Appendf(nil, "%s %s", name, status), nil
+	default:
+		return nil, fmt.Errorf("invalid %s: unknown status %q", name, status)
+	}
+}
+
+// unmarshalStatus parses a message with a status.
+func unmarshalStatus(name string, text []byte) (string, error) {
+	fields := strings.Fields(string(text))
+
+	if len(fields) != 2 || fields[0] != name {
+		return "", fmt.Errorf("invalid %s", name)
+	}
+
+	switch fields[1] {
+	case StatusChecking, StatusOK, StatusError:
+		return fields[1], nil
+	default:
+		return "", fmt.Errorf("invalid %s: unknown status %q", name, fields[1])
+	}
+}
+
+// CopyProtection represents the "copyprotection" message.
+type CopyProtection struct {
+	Status string // One of the Status constants.
+}
+
+func (*CopyProtection) response() {}
+
+func (c *CopyProtection) MarshalText() ([]byte, error) {
+	return marshalStatus("copyprotection", c.Status)
+}
+
+func (c *CopyProtection) UnmarshalText(text []byte) error {
+	status, err := unmarshalStatus("copyprotection", text)
+	if err != nil {
+		return err
+	}
+	c.Status = status
+	return nil
+}
+
+// Debug represents the "debug" message.
+type Debug struct {
+	Flag bool
+}
+
+func (*Debug) request() {}
+
+func (d *Debug) MarshalText() ([]byte, error) {
+	if d.Flag {
+		return []byte("debug on"), nil
+	}
+	return []byte("debug off"), nil
+}
+
+func (d *Debug) UnmarshalText(text []byte) error {
+	switch strings.Join(strings.Fields(string(text)), " ") {
+	case "debug on":
+		d.Flag = true
+	case "debug off":
+		d.Flag = false
+	default:
+		return fmt.Errorf("invalid debug")
+	}
+	return nil
+}
+
+// Go represents the "go" message.
+type Go struct {
+	SearchMoves []chess.Move // Restrict search to these moves only. Ignore if empty.
+
+	Ponder   bool // Search in pondering mode.
+	Infinite bool // Search until interrupted.
+	MoveTime int  // If > 0, search for this many milliseconds.
+
+	WhiteTime      int // If > 0, white's remaining time in milliseconds.
+	BlackTime      int // If > 0, black's remaining time in milliseconds.
+	WhiteIncrement int // If > 0, white's increment in milliseconds.
+	BlackIncrement int // If > 0, black's increment in milliseconds.
+
+	Depth     int // If > 0, search this many plies only.
+	Nodes     int // If > 0, search this many nodes only.
+	Mate      int // If > 0, search for a mate in this many moves.
+	MovesToGo int // If > 0, there are this many moves until the next time control.
+}
+
+func (*Go) request() {}
+
+// goInts returns the integer parameters of g, in the order they are encoded.
+func (g *Go) goInts() []struct {
+	name  string
+	value *int
+} {
+	return []struct {
+		name  string
+		value *int
+	}{
+		{"wtime", &g.WhiteTime},
+		{"btime", &g.BlackTime},
+		{"winc", &g.WhiteIncrement},
+		{"binc", &g.BlackIncrement},
+		{"movestogo", &g.MovesToGo},
+		{"depth", &g.Depth},
+		{"nodes", &g.Nodes},
+		{"mate", &g.Mate},
+		{"movetime", &g.MoveTime},
+	}
+}
+
+func (g *Go) MarshalText() ([]byte, error) {
+	text := []byte("go")
+
+	if len(g.SearchMoves) > 0 {
+		text = appendMoves(append(text, " searchmoves "...), g.SearchMoves)
+	}
+
+	if g.Ponder {
+		text = fmt.Appendf(text, " ponder")
+	}
+
+	for _, p := range g.goInts() {
+		if *p.value > 0 {
+			text = fmt.Appendf(text, " %s %d", p.name, *p.value)
+		}
+	}
+
+	if g.Infinite {
+		text = fmt.Appendf(text, " infinite")
+	}
+
+	return text, nil
+}
+
+func (g *Go) UnmarshalText(text []byte) error {
+	fields := strings.Fields(string(text))
+
+	if len(fields) == 0 || fields[0] != "go" {
+		return fmt.Errorf("invalid go")
+	}
+
+	*g = Go{}
+
+	ints := make(map[string]*int)
+	for _, p := range g.goInts() {
+		ints[p.name] = p.value
+	}
+
+	for n := 1; n < len(fields); n++ {
+		switch f := fields[n]; f {
+		case "ponder":
+			g.Ponder = true
+		case "infinite":
+			g.Infinite = true
+		case "searchmoves":
+			// Moves continue until the next parameter. GUIs may send none.
+			start := n + 1
+			for n+1 < len(fields) && !isGoParameter(fields[n+1]) {
+				n++
+			}
+			moves, err := parseMoves(fields[start : n+1])
+			if err != nil {
+				return fmt.Errorf("invalid go: %w", err)
+			}
+			g.SearchMoves = moves
+		default:
+			p, ok := ints[f]
+			if !ok {
+				return fmt.Errorf("invalid go: unknown parameter %q", f)
+			}
+			if n+1 == len(fields) {
+				return fmt.Errorf("invalid go: missing value for %s", f)
+			}
+			n++
+			v, err := strconv.Atoi(fields[n])
+			if err != nil {
+				return fmt.Errorf("invalid go: bad %s %q", f, fields[n])
+			}
+			switch f {
+			case "depth", "nodes", "movetime":
+				if v < 0 {
+					return fmt.Errorf("invalid go: bad %s %q", f, fields[n])
+				}
+			case "wtime", "btime":
+				// GUIs send a clock at or below zero once an engine overruns
+				// it. The engine must still move, so leave it a minimal budget.
+				if v < minClock {
+					v = minClock
+				}
+			default:
+				if v < 0 {
+					v = 0
+				}
+			}
+			*p = v
+		}
+	}
+
+	return nil
+}
+
+// minClock is the remaining time in milliseconds that a "go" message with an
+// expired clock is read as.
+const minClock = 1
+
+// isGoParameter reports whether s is a parameter name of the "go" message.
+func isGoParameter(s string) bool {
+	switch s {
+	case "searchmoves", "ponder", "wtime", "btime", "winc", "binc", "movestogo",
+		"depth", "nodes", "mate", "movetime", "infinite":
+		return true
+	default:
+		return false
+	}
+}
+
+// ID represents the "id" message. The name and the author are sent on separate
+// lines, so exactly one of them is set.
+type ID struct {
+	Name   string
+	Author string
+}
+
+func (*ID) response() {}
+
+func (i *ID) MarshalText() ([]byte, error) {
+	switch {
+	case i.Name != "" && i.Author == "":
+		return fmt.Appendf(nil, "id name %s", i.Name), nil
+	case i.Name == "" && i.Author != "":
+		return fmt.Appendf(nil, "id author %s", i.Author), nil
+	default:
+		return nil, fmt.Errorf("invalid id: want exactly one of name and author")
+	}
+}
+
+func (i *ID) UnmarshalText(text []byte) error {
+	fields := strings.Fields(string(text))
+
+	if len(fields) < 3 || fields[0] != "id" {
+		return fmt.Errorf("invalid id")
+	}
+
+	value := strings.Join(fields[2:], " ")
+
+	switch fields[1] {
+	case "name":
+		*i = ID{Name: value}
+	case "author":
+		*i = ID{Author: value}
+	default:
+		return fmt.Errorf("invalid id: unknown field %q", fields[1])
+	}
+
+	return nil
+}
+
+// Score types used in [Info].
+const (
+	ScoreTypeCentipawn = "cp"
+	ScoreTypeMate      = "mate"
+)
+
+// Score bounds used in [Info].
+const (
+	ScoreBoundLower = "lowerbound"
+	ScoreBoundUpper = "upperbound"
+)
+
+// Info represents the "info" message.
+type Info struct {
+	Depth    int // If > 0, search depth in plies.
+	SelDepth int // If > 0, selective search depth in plies.
+	MultiPV  int // If > 0, the line number in MultiPV mode, starting at 1.
+
+	Score      int    // Score from the engine's point of view.
+	ScoreType  string // ScoreTypeCentipawn, ScoreTypeMate, or empty for no score.
+	ScoreBound string // ScoreBoundLower, ScoreBoundUpper, or empty for an exact score.
+
+	Nodes    int // If > 0, number of nodes searched.
+	NPS      int // If > 0, nodes searched per second.
+	Time     int // If > 0, time searched in milliseconds.
+	HashFull int // If > 0, how full the hash table is, in permille.
+	TBHits   int // If > 0, number of positions found in the endgame tablebases.
+	SBHits   int // If > 0, number of positions found in the shredder bases.
+	CPULoad  int // If > 0, CPU usage of the engine, in permille.
+
+	CurrMove       chess.Move // The move currently searched. Ignored if it's the null move.
+	CurrMoveNumber int        // If > 0, the number of the move currently searched, starting at 1.
+
+	Refutation  []chess.Move // A move followed by the line that refutes it.
+	CurrLineCPU int          // If > 0, the CPU searching CurrLine, starting at 1.
+	CurrLine    []chess.Move // The line currently searched.
+	PV          []chess.Move // Moves in the principal variation.
+
+	String string // If not empty, any text to display. It is always last.
+}
+
+func (*Info) response() {}
+
+// hasFields reports whether i has any field to send. An info message without
+// fields is invalid.
+func (i *Info) hasFields() bool {
+	return i.Depth > 0 || i.SelDepth > 0 || i.MultiPV > 0 || i.ScoreType != "" ||
+		i.Nodes > 0 || i.NPS > 0 || i.Time > 0 || i.HashFull > 0 || i.TBHits > 0 ||
+		i.SBHits > 0 || i.CPULoad > 0 || i.CurrMove != (chess.Move{}) ||
+		i.CurrMoveNumber > 0 || len(i.Refutation) > 0 || len(i.CurrLine) > 0 ||
+		len(i.PV) > 0 || i.String != ""
+}
+
+func (i *Info) MarshalText() ([]byte, error) {
+	if !i.hasFields() {
+		return nil, fmt.Errorf("invalid info: no fields")
+	}
+
+	text := []byte("info")
+
+	ints := []struct {
+		name  string
+		value int
+	}{
+		{"depth", i.Depth},
+		{"seldepth", i.SelDepth},
+		{"multipv", i.MultiPV},
+	}
+	for _, v := range ints {
+		if v.value > 0 {
+			text = fmt.Appendf(text, " %s %d", v.name, v.value)
+		}
+	}
+
+	switch i.ScoreType {
+	case "":
+		if i.ScoreBound != "" {
+			return nil, fmt.Errorf("invalid info: score bound without a score")
+		}
+	case ScoreTypeCentipawn, ScoreTypeMate:
+		text = fmt.Appendf(text, " score %s %d", i.ScoreType, i.Score)
+	default:
+		return nil, fmt.Errorf("invalid info: unknown score type %q", i.ScoreType)
+	}
+
+	switch i.ScoreBound {
+	case "":
+		// Exact score.
+	case ScoreBoundLower, ScoreBoundUpper:
+		text = fmt.Appendf(text, " %s", i.ScoreBound)
+	default:
+		return nil, fmt.Errorf("invalid info: unknown score bound %q", i.ScoreBound)
+	}
+
+	ints = []struct {
+		name  string
+		value int
+	}{
+		{"nodes", i.Nodes},
+		{"nps", i.NPS},
+		{"time", i.Time},
+		{"hashfull", i.HashFull},
+		{"tbhits", i.TBHits},
+		{"sbhits", i.SBHits},
+		{"cpuload", i.CPULoad},
+	}
+	for _, v := range ints {
+		if v.value > 0 {
+			text = fmt.Appendf(text, " %s %d", v.name, v.value)
+		}
+	}
+
+	if i.CurrMove != (chess.Move{}) {
+		text = fmt.Appendf(text, " currmove %s", i.CurrMove.LAN())
+	}
+
+	if i.CurrMoveNumber > 0 {
+		text = fmt.Appendf(text, " currmovenumber %d", i.CurrMoveNumber)
+	}
+
+	if len(i.Refutation) > 0 {
+		text = appendMoves(append(text, " refutation "...), i.Refutation)
+	}
+
+	if len(i.CurrLine) > 0 {
+		text = fmt.Appendf(text, " currline")
+		if i.CurrLineCPU > 0 {
+			text = fmt.Appendf(text, " %d", i.CurrLineCPU)
+		}
+		text = appendMoves(append(text, ' '), i.CurrLine)
+	} else if i.CurrLineCPU > 0 {
+		return nil, fmt.Errorf("invalid info: currline cpu without a line")
+	}
+
+	if len(i.PV) > 0 {
+		text = appendMoves(append(text, " pv "...), i.PV)
+	}
+
+	if i.String != "" {
+		text = fmt.Appendf(text, " string %s", i.String)
+	}
+
+	return text, nil
+}
+
+// infoKeywords are the tokens that start a field of an info message. They
+// also end the move lists of the previous field.
+var infoKeywords = map[string]bool{
+	"depth":          true,
+	"seldepth":       true,
+	"time":           true,
+	"nodes":          true,
+	"pv":             true,
+	"multipv":        true,
+	"score":          true,
+	"currmove":       true,
+	"currmovenumber": true,
+	"hashfull":       true,
+	"nps":            true,
+	"tbhits":         true,
+	"sbhits":         true,
+	"cpuload":        true,
+	"string":         true,
+	"refutation":     true,
+	"currline":       true,
+}
+
+func (i *Info) UnmarshalText(text []byte) error {
+	*i = Info{}
+
+	// The string field takes the rest of the line verbatim, including any
+	// keywords.
+	s := string(text)
+	if before, after, ok := strings.Cut(s, " string "); ok {
+		s, i.String = before, after
+	}
+
+	fields := strings.Fields(s)
+
+	if len(fields) == 0 || fields[0] != "info" {
+		return fmt.Errorf("invalid info")
+	}
+
+	// next returns the value of the field at fields[n].
+	next := func(n int) (string, error) {
+		if n+1 >= len(fields) {
+			return "", fmt.Errorf("invalid info: missing value for %s", fields[n])
+		}
+		return fields[n+1], nil
+	}
+
+	// moves parses the moves starting at fields[n], up to the next keyword,
+	// and returns the index of the field after them.
+	moves := func(n int, dst *[]chess.Move) (int, error) {
+		end := n
+		for end < len(fields) && !infoKeywords[fields[end]] {
+			end++
+		}
+		ms, err := parseMoves(fields[n:end])
+		if err != nil {
+			return 0, fmt.Errorf("invalid info: %w", err)
+		}
+		*dst = ms
+		return end, nil
+	}
+
+	ints := map[string]*int{
+		"depth":          &i.Depth,
+		"seldepth":       &i.SelDepth,
+		"time":           &i.Time,
+		"nodes":          &i.Nodes,
+		"multipv":        &i.MultiPV,
+		"currmovenumber": &i.CurrMoveNumber,
+		"hashfull":       &i.HashFull,
+		"nps":            &i.NPS,
+		"tbhits":         &i.TBHits,
+		"sbhits":         &i.SBHits,
+		"cpuload":        &i.CPULoad,
+	}
+
+	for n := 1; n < len(fields); {
+		key := fields[n]
+
+		if p, ok := ints[key]; ok {
+			v, err := next(n)
+			if err != nil {
+				return err
+			}
+			if *p, err = strconv.Atoi(v); err != nil || *p < 0 {
+				return fmt.Errorf("invalid info: bad %s %q", key, v)
+			}
+			n += 2
+			continue
+		}
+
+		switch key {
+		case "score":
+			typ, err := next(n)
+			if err != nil {
+				return err
+			}
+			if typ != ScoreTypeCentipawn && typ != ScoreTypeMate {
+				return fmt.Errorf("invalid info: unknown score type %q", typ)
+			}
+			v, err := next(n + 1)
+			if err != nil {
+				return err
+			}
+			if i.Score, err = strconv.Atoi(v); err != nil {
+				return fmt.Errorf("invalid info: bad score %q", v)
+			}
+			i.ScoreType = typ
+			n += 3
+			if n < len(fields) && (fields[n] == ScoreBoundLower || fields[n] == ScoreBoundUpper) {
+				i.ScoreBound = fields[n]
+				n++
+			}
+		case "currmove":
+			v, err := next(n)
+			if err != nil {
+				return err
+			}
+			if i.CurrMove, err = parseMove(v); err != nil {
+				return fmt.Errorf("invalid info: %s: %w", v, err)
+			}
+			n += 2
+		case "pv", "refutation":
+			dst := &i.PV
+			if key == "refutation" {
+				dst = &i.Refutation
+			}
+			var err error
+			if n, err = moves(n+1, dst); err != nil {
+				return err
+			}
+		case "currline":
+			n++
+			if n < len(fields) {
+				if cpu, err := strconv.Atoi(fields[n]); err == nil {
+					if cpu <= 0 {
+						return fmt.Errorf("invalid info: bad currline cpu %q", fields[n])
+					}
+					i.CurrLineCPU = cpu
+					n++
+				}
+			}
+			var err error
+			if n, err = moves(n, &i.CurrLine); err != nil {
+				return err
+			}
+			if i.CurrLineCPU > 0 && len(i.CurrLine) == 0 {
+				return fmt.Errorf("invalid info: currline cpu without a line")
+			}
+		case "string":
+			// Only reachable for an empty string at the end of the line.
+			n++
+		default:
+			return fmt.Errorf("invalid info: unknown field %q", key)
+		}
+	}
+
+	if !i.hasFields() {
+		return fmt.Errorf("invalid info: no fields")
+	}
+
+	return nil
+}
+
+// IsReady represents the "isready" message.
+type IsReady struct{}
+
+func (*IsReady) request() {}
+
+func (*IsReady) MarshalText() ([]byte, error) {
+	return []byte("isready"), nil
+}
+
+func (*IsReady) UnmarshalText(text []byte) error {
+	if string(text) != "isready" {
+		return fmt.Errorf("invalid isready message")
+	}
+	return nil
+}
+
+// Option types used in [Option].
+const (
+	OptionTypeCheck  = "check"
+	OptionTypeSpin   = "spin"
+	OptionTypeCombo  = "combo"
+	OptionTypeButton = "button"
+	OptionTypeString = "string"
+)
+
+// Option represents the "option" message.
+type Option struct {
+	Name    string
+	Type    string   // One of the OptionType constants.
+	Default string   // Default value. Unused for buttons.
+	Min     int      // Minimum value, for spin options only.
+	Max     int      // Maximum value, for spin options only.
+	Vars    []string // Allowed values, for combo options only.
+}
+
+func (*Option) response() {}
+
+func (o *Option) MarshalText() ([]byte, error) {
+	if o.Name == "" {
+		return nil, fmt.Errorf("invalid option: name is empty")
+	}
+
+	text := fmt.Appendf(nil, "option name %s type %s", o.Name, o.Type)
+
+	switch o.Type {
+	case OptionTypeButton:
+		// No default value.
+	case OptionTypeString:
+		if o.Default == "" {
+			text = fmt.Appendf(text, " default <empty>")
+		} else {
+			text = fmt.Appendf(text, " default %s", o.Default)
+		}
+	case OptionTypeCheck, OptionTypeSpin, OptionTypeCombo:
+		if o.Default != "" {
+			text = fmt.Appendf(text, " default %s", o.Default)
+		}
+	default:
+		return nil, fmt.Errorf("invalid option: unknown type %q", o.Type)
+	}
+
+	if o.Type == OptionTypeSpin {
+		text = fmt.Appendf(text, " min %d max %d", o.Min, o.Max)
+	}
+
+	if o.Type == OptionTypeCombo {
+		for _, v := range o.Vars {
+			text = fmt.Appendf(text, " var %s", v)
+		}
+	}
+
+	return text, nil
+}
+
+func (o *Option) UnmarshalText(text []byte) error {
+	fields := strings.Fields(string(text))
+
+	if len(fields) < 2 || fields[0] != "option" || fields[1] != "name" {
+		return fmt.Errorf("invalid option")
+	}
+
+	*o = Option{}
+
+	// The name runs until the type, and may contain spaces.
+	n := 2
+	for n < len(fields) && fields[n] != "type" {
+		n++
+	}
+	if n == 2 || n+1 >= len(fields) {
+		return fmt.Errorf("invalid option: missing name or type")
+	}
+	o.Name = strings.Join(fields[2:n], " ")
+	o.Type = fields[n+1]
+
+	switch o.Type {
+	case OptionTypeCheck, OptionTypeSpin, OptionTypeCombo, OptionTypeButton, OptionTypeString:
+	default:
+		return fmt.Errorf("invalid option: unknown type %q", o.Type)
+	}
+
+	// Values run until the next keyword, and may contain spaces.
+	value := func(start int) (string, int) {
+		end := start
+		for end < len(fields) && !isOptionKeyword(fields[end]) {
+			end++
+		}
+		return strings.Join(fields[start:end], " "), end
+	}
+
+	var hasMin, hasMax bool
+
+	for n += 2; n < len(fields); {
+		key := fields[n]
+
+		var v string
+		v, n = value(n + 1)
+
+		switch key {
+		case "default":
+			if o.Type == OptionTypeString && v == "<empty>" {
+				v = ""
+			}
+			o.Default = v
+		case "min", "max":
+			i, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("invalid option: bad %s %q", key, v)
+			}
+			if key == "min" {
+				o.Min, hasMin = i, true
+			} else {
+				o.Max, hasMax = i, true
+			}
+		case "var":
+			o.Vars = append(o.Vars, v)
+		default:
+			return fmt.Errorf("invalid option: unknown field %q", key)
+		}
+	}
+
+	if o.Type == OptionTypeSpin && !(hasMin && hasMax) {
+		return fmt.Errorf("invalid option: spin option without min and max")
+	}
+
+	return nil
+}
+
+// isOptionKeyword reports whether s is a field name of the "option" message,
+// after the type.
+func isOptionKeyword(s string) bool {
+	switch s {
+	case "default", "min", "max", "var":
+		return true
+	default:
+		return false
+	}
+}
+
+// PonderHit represents the "ponderhit" message.
+type PonderHit struct{}
+
+func (*PonderHit) request() {}
+
+func (*PonderHit) MarshalText() ([]byte, error) {
+	return []byte("ponderhit"), nil
+}
+
+func (*PonderHit) UnmarshalText(text []byte) error {
+	if string(text) != "ponderhit" {
+		return fmt.Errorf("invalid ponderhit message")
+	}
+	return nil
+}
+
+// Position represents the "position" message.
+type Position struct {
+	Start chess.Position // The position before Moves, from "startpos" or a FEN.
+	Moves []chess.Move   // Moves played from Start. They are not checked for legality.
+}
+
+func (*Position) request() {}
+
+func (p *Position) MarshalText() ([]byte, error) {
+	if p.Start == (chess.Position{}) {
+		return nil, fmt.Errorf("invalid position: no starting position")
+	}
+
+	f, err := fen.Encode(p.Start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid position: %w", err)
+	}
+
+	var text []byte
+
+	if f == fen.StartingFEN {
+		text = []byte("position startpos")
+	} else {
+		text = fmt.Appendf(text, "position fen %s", f)
+	}
+
+	if len(p.Moves) > 0 {
+		text = appendMoves(append(text, " moves "...), p.Moves)
+	}
+
+	return text, nil
+}
+
+func (p *Position) UnmarshalText(text []byte) error {
+	fields := strings.Fields(string(text))
+
+	if len(fields) < 2 || fields[0] != "position" {
+		return fmt.Errorf("invalid position")
+	}
+
+	*p = Position{}
+
+	// The FEN runs until the moves.
+	n := 2
+	switch fields[1] {
+	case "startpos":
+		p.Start = chess.NewPosition()
+	case "fen":
+		for n < len(fields) && fields[n] != "moves" {
+			n++
+		}
+		if n == 2 {
+			return fmt.Errorf("invalid position: fen is empty")
+		}
+		start, err := fen.Decode(strings.Join(fields[2:n], " "))
+		if err != nil {
+			return fmt.Errorf("invalid position: %w", err)
+		}
+		if err := start.IsValid(); err != nil {
+			return fmt.Errorf("invalid position: %w", err)
+		}
+		p.Start = start
+	default:
+		return fmt.Errorf("invalid position: unknown field %q", fields[1])
+	}
+
+	if n < len(fields) {
+		if fields[n] != "moves" {
+			return fmt.Errorf("invalid position: unknown field %q", fields[n])
+		}
+		// GUIs commonly send "moves" with nothing after it.
+		moves, err := parseMoves(fields[n+1:])
+		if err != nil {
+			return fmt.Errorf("invalid position: %w", err)
+		}
+		p.Moves = moves
+	}
+
+	return nil
+}
+
+// Quit represents the "quit" message.
+type Quit struct{}
+
+func (*Quit) request() {}
+
+func (*Quit) MarshalText() ([]byte, error) {
+	return []byte("quit"), nil
+}
+
+func (*Quit) UnmarshalText(text []byte) error {
+	if string(text) != "quit" {
+		return fmt.Errorf("invalid quit command")
+	}
+	return nil
+}
+
+// ReadyOk represents the "readyok" message.
+type ReadyOk struct{}
+
+func (*ReadyOk) response() {}
+
+func (*ReadyOk) MarshalText() ([]byte, error) {
+	return []byte("readyok"), nil
+}
+
+func (*ReadyOk) UnmarshalText(text []byte) error {
+	if string(text) != "readyok" {
+		return fmt.Errorf("invalid readyok message")
+	}
+	return nil
+}
+
+// Register represents the "register" message.
+type Register struct {
+	Later bool
+	Name  string
+	Code  string
+}
+
+func (*Register) request() {}
+
+func (r *Register) MarshalText() ([]byte, error) {
+	if r.Later {
+		if r.Name != "" || r.Code != "" {
+			return nil, fmt.Errorf("invalid register: later with a name or code")
+		}
+		return []byte("register later"), nil
+	}
+
+	if r.Name == "" && r.Code == "" {
+		return nil, fmt.Errorf("invalid register: no name or code")
+	}
+
+	text := []byte("register")
+
+	if r.Name != "" {
+		text = fmt.Appendf(text, " name %s", r.Name)
+	}
+
+	if r.Code != "" {
+		text = fmt.Appendf(text, " code %s", r.Code)
+	}
+
+	return text, nil
+}
+
+func (r *Register) UnmarshalText(text []byte) error {
+	fields := strings.Fields(string(text))
+
+	if len(fields) < 2 || fields[0] != "register" {
+		return fmt.Errorf("invalid register")
+	}
+
+	*r = Register{}
+
+	if len(fields) == 2 && fields[1] == "later" {
+		r.Later = true
+		return nil
+	}
+
+	// The name and code run until the next keyword, and may contain spaces.
+	for n := 1; n < len(fields); {
+		key := fields[n]
+		end := n + 1
+		for end < len(fields) && fields[end] != "name" && fields[end] != "code" {
+			end++
+		}
+		if end == n+1 {
+			return fmt.Errorf("invalid register: missing value for %s", key)
+		}
+		value := strings.Join(fields[n+1:end], " ")
+
+		switch key {
+		case "name":
+			r.Name = value
+		case "code":
+			r.Code = value
+		default:
+			return fmt.Errorf("invalid register: unknown field %q", key)
+		}
+
+		n = end
+	}
+
+	return nil
+}
+
+// Registration represents the "registration" message.
+type Registration struct {
+	Status string // One of the Status constants.
+}
+
+func (*Registration) response() {}
+
+func (r *Registration) MarshalText() ([]byte, error) {
+	return marshalStatus("registration", r.Status)
+}
+
+func (r *Registration) UnmarshalText(text []byte) error {
+	status, err := unmarshalStatus("registration", text)
+	if err != nil {
+		return err
+	}
+	r.Status = status
+	return nil
+}
+
+// SetOption represents the "setoption" message.
+type SetOption struct {
+	Name  string
+	Value string
+}
+
+func (*SetOption) request() {}
+
+func (s *SetOption) MarshalText() ([]byte, error) {
+	if s.Name == "" {
+		return nil, fmt.Errorf("invalid setoption: name is empty")
+	}
+
+	text := fmt.Appendf(nil, "setoption name %s", s.Name)
+
+	if s.Value != "" {
+		text = fmt.Appendf(text, " value %s", s.Value)
+	}
+
+	return text, nil
+}
+
+func (s *SetOption) UnmarshalText(text []byte) error {
+	fields := strings.Fields(string(text))
+
+	if len(fields) < 3 || fields[0] != "setoption" || fields[1] != "name" {
+		return fmt.Errorf("invalid setoption")
+	}
+
+	*s = SetOption{}
+
+	// The name runs until the value, and both may contain spaces.
+	n := 2
+	for n < len(fields) && fields[n] != "value" {
+		n++
+	}
+	if n == 2 {
+		return fmt.Errorf("invalid setoption: name is empty")
+	}
+
+	s.Name = strings.Join(fields[2:n], " ")
+	if n < len(fields) {
+		s.Value = strings.Join(fields[n+1:], " ")
+	}
+
+	return nil
+}
+
+// Stop represents the "stop" message.
+type Stop struct{}
+
+func (*Stop) request() {}
+
+func (*Stop) MarshalText() ([]byte, error) {
+	return []byte("stop"), nil
+}
+
+func (*Stop) UnmarshalText(text []byte) error {
+	if string(text) != "stop" {
+		return fmt.Errorf("invalid stop message")
+	}
+	return nil
+}
+
+// UCI represents the "uci" message.
+type UCI struct{}
+
+func (*UCI) request() {}
+
+func (*UCI) MarshalText() ([]byte, error) {
+	return []byte("uci"), nil
+}
+
+func (*UCI) UnmarshalText(text []byte) error {
+	if string(text) != "uci" {
+		return fmt.Errorf("invalid uci message")
+	}
+	return nil
+}
+
+// UCINewGame represents the "ucinewgame" message.
+type UCINewGame struct{}
+
+func (*UCINewGame) request() {}
+
+func (*UCINewGame) MarshalText() ([]byte, error) {
+	return []byte("ucinewgame"), nil
+}
+
+func (*UCINewGame) UnmarshalText(text []byte) error {
+	if string(text) != "ucinewgame" {
+		return fmt.Errorf("invalid ucinewgame message")
+	}
+	return nil
+}
+
+// UCIOk represents the "uciok" message.
+type UCIOk struct{}
+
+func (*UCIOk) response() {}
+
+func (*UCIOk) MarshalText() ([]byte, error) {
+	return []byte("uciok"), nil
+}
+
+func (*UCIOk) UnmarshalText(text []byte) error {
+	if string(text) != "uciok" {
+		return fmt.Errorf("invalid uciok message")
+	}
+	return nil
+}
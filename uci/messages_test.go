@@ -0,0 +1,428 @@
+package uci
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/clfs/aloe/chess"
+	"github.com/clfs/aloe/fen"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// move parses a move in long algebraic notation, panicking on error.
+func move(s string) chess.Move {
+	m, err := chess.NewMove(s)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// lan parses moves in long algebraic notation, panicking on error.
+func lan(ss ...string) []chess.Move {
+	var moves []chess.Move
+	for _, s := range ss {
+		moves = append(moves, move(s))
+	}
+	return moves
+}
+
+// position decodes a FEN, panicking on error.
+func position(s string) chess.Position {
+	p, err := fen.Decode(s)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// cmpOpts compares messages. Positions have unexported fields, but are
+// comparable.
+var cmpOpts = []cmp.Option{
+	cmpopts.EquateEmpty(),
+	cmp.Comparer(func(a, b chess.Position) bool { return a == b }),
+}
+
+type marshalTextTest struct {
+	in  Message
+	out string
+	err error
+}
+
+var marshalTextTests = []marshalTextTest{
+	{
+		in:  &BestMove{Move: move("e2e4")},
+		out: "bestmove e2e4",
+	},
+	{
+		in:  &BestMove{Move: move("e2e4"), Ponder: move("e7e5")},
+		out: "bestmove e2e4 ponder e7e5",
+	},
+	{
+		in:  &BestMove{Move: move("e2e4"), Ponder: move("e7e5")},
+		out: "bestmove e2e4 ponder e7e5",
+	},
+	{
+		in:  &Info{Depth: 3, Score: 25, ScoreType: ScoreTypeCentipawn, PV: lan("e2e4", "e7e5")},
+		out: "info depth 3 score cp 25 pv e2e4 e7e5",
+	},
+	{
+		in:  &Info{Depth: 5, Score: -2, ScoreType: ScoreTypeMate, ScoreBound: ScoreBoundUpper},
+		out: "info depth 5 score mate -2 upperbound",
+	},
+	{
+		in:  &Info{CurrMove: move("e2e4"), CurrMoveNumber: 1},
+		out: "info currmove e2e4 currmovenumber 1",
+	},
+	{
+		in:  &Info{String: "hello world"},
+		out: "info string hello world",
+	},
+}
+
+var infoTests = []struct {
+	text string
+	info Info
+}{
+	{
+		text: "info depth 12 seldepth 20 multipv 2 score cp -31 lowerbound nodes 123456 nps 987654 time 125 hashfull 42 tbhits 7 sbhits 3 cpuload 950 pv e2e4 e7e5 g1f3",
+		info: Info{
+			Depth: 12, SelDepth: 20, MultiPV: 2,
+			Score: -31, ScoreType: ScoreTypeCentipawn, ScoreBound: ScoreBoundLower,
+			Nodes: 123456, NPS: 987654, Time: 125, HashFull: 42, TBHits: 7, SBHits: 3, CPULoad: 950,
+			PV: lan("e2e4", "e7e5", "g1f3"),
+		},
+	},
+	{
+		text: "info score mate 3",
+		info: Info{Score: 3, ScoreType: ScoreTypeMate},
+	},
+	{
+		text: "info score cp 0",
+		info: Info{ScoreType: ScoreTypeCentipawn},
+	},
+	{
+		text: "info depth 2 currmove e7e8q currmovenumber 14",
+		info: Info{Depth: 2, CurrMove: move("e7e8q"), CurrMoveNumber: 14},
+	},
+	{
+		text: "info refutation d1h5 g6h5",
+		info: Info{Refutation: lan("d1h5", "g6h5")},
+	},
+	{
+		text: "info currline d1h5 g6h5",
+		info: Info{CurrLine: lan("d1h5", "g6h5")},
+	},
+	{
+		text: "info currline 2 d1h5 g6h5 pv d1h5",
+		info: Info{CurrLineCPU: 2, CurrLine: lan("d1h5", "g6h5"), PV: lan("d1h5")},
+	},
+	{
+		text: "info depth 1 pv e2e4 string multiple  spaces and depth 5 pv d2d4",
+		info: Info{Depth: 1, PV: lan("e2e4"), String: "multiple  spaces and depth 5 pv d2d4"},
+	},
+}
+
+func TestInfo(t *testing.T) {
+	for _, tt := range infoTests {
+		var got Info
+		if err := got.UnmarshalText([]byte(tt.text)); err != nil {
+			t.Errorf("%q: unmarshal: %v", tt.text, err)
+			continue
+		}
+		if diff := cmp.Diff(tt.info, got, cmpOpts...); diff != "" {
+			t.Errorf("%q: unmarshal (-want, +got)\n%s", tt.text, diff)
+		}
+
+		text, err := tt.info.MarshalText()
+		if err != nil {
+			t.Errorf("%q: marshal: %v", tt.text, err)
+			continue
+		}
+		if string(text) != tt.text {
+			t.Errorf("marshal: got %q, want %q", text, tt.text)
+		}
+	}
+}
+
+func TestInfo_UnmarshalText_Invalid(t *testing.T) {
+	cases := []string{
+		"",
+		"info",
+		"info string",
+		"info depth 0",
+		"bestmove e2e4",
+		"info depth",
+		"info depth x",
+		"info nodes -5",
+		"info currline 0 e2e4",
+		"info score",
+		"info score cp",
+		"info score wdl 500",
+		"info score cp x",
+		"info currmove",
+		"info currmove e2",
+		"info pv e2e4 e7e9",
+		"info refutation d1h5 x",
+		"info currline 1 d1h5 g6h5z",
+		"info currline 1 pv e2e4",
+		"info bogus 1",
+	}
+
+	for _, c := range cases {
+		var info Info
+		if err := info.UnmarshalText([]byte(c)); err == nil {
+			t.Errorf("%q: expected error, got %+v", c, info)
+		}
+	}
+}
+
+func TestInfo_MarshalText_Invalid(t *testing.T) {
+	cases := []Info{
+		{},
+		{ScoreType: "wdl"},
+		{ScoreBound: ScoreBoundLower},
+		{ScoreType: ScoreTypeCentipawn, ScoreBound: "exact"},
+		{CurrLineCPU: 1},
+	}
+
+	for _, c := range cases {
+		if text, err := c.MarshalText(); err == nil {
+			t.Errorf("%+v: expected error, got %q", c, text)
+		}
+	}
+}
+
+func TestMarshalText(t *testing.T) {
+	for i, tt := range marshalTextTests {
+		got, err := tt.in.MarshalText()
+
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%d: error %v, want %v", i, err, tt.err)
+		}
+
+		if string(got) != tt.out {
+			t.Errorf("%d: got %q, want %q", i, got, tt.out)
+		}
+	}
+}
+
+// roundTripTests are messages with their canonical encodings.
+var roundTripTests = []struct {
+	text string
+	msg  Message
+}{
+	{"bestmove 0000", &BestMove{}},
+	{"bestmove e7e8n ponder f7f6", &BestMove{Move: move("e7e8n"), Ponder: move("f7f6")}},
+	{"copyprotection checking", &CopyProtection{Status: StatusChecking}},
+	{"copyprotection error", &CopyProtection{Status: StatusError}},
+	{"debug on", &Debug{Flag: true}},
+	{"debug off", &Debug{}},
+	{"go", &Go{}},
+	{"go infinite", &Go{Infinite: true}},
+	{"go ponder wtime 1000 btime 2000 winc 10 binc 20 movestogo 5", &Go{Ponder: true, WhiteTime: 1000, BlackTime: 2000, WhiteIncrement: 10, BlackIncrement: 20, MovesToGo: 5}},
+	{"go searchmoves e2e4 d2d4 depth 6 nodes 1000 mate 3 movetime 500", &Go{SearchMoves: lan("e2e4", "d2d4"), Depth: 6, Nodes: 1000, Mate: 3, MoveTime: 500}},
+	{"id name Aloe 1.0", &ID{Name: "Aloe 1.0"}},
+	{"id author the Aloe authors", &ID{Author: "the Aloe authors"}},
+	{"option name Hash type spin default 16 min 1 max 65536", &Option{Name: "Hash", Type: OptionTypeSpin, Default: "16", Min: 1, Max: 65536}},
+	{"option name Clear Hash type button", &Option{Name: "Clear Hash", Type: OptionTypeButton}},
+	{"option name Ponder type check default false", &Option{Name: "Ponder", Type: OptionTypeCheck, Default: "false"}},
+	{"option name EvalFile type string default <empty>", &Option{Name: "EvalFile", Type: OptionTypeString}},
+	{"option name Book File type string default my book.bin", &Option{Name: "Book File", Type: OptionTypeString, Default: "my book.bin"}},
+	{"option name Style type combo default Very Solid var Very Solid var Normal var Risky", &Option{Name: "Style", Type: OptionTypeCombo, Default: "Very Solid", Vars: []string{"Very Solid", "Normal", "Risky"}}},
+	{"option name Contempt type spin default -10 min -100 max 100", &Option{Name: "Contempt", Type: OptionTypeSpin, Default: "-10", Min: -100, Max: 100}},
+	{"position startpos", &Position{Start: position(fen.StartingFEN)}},
+	{"position startpos moves e2e4 e7e5", &Position{Start: position(fen.StartingFEN), Moves: lan("e2e4", "e7e5")}},
+	{"position fen 8/8/8/8/8/8/8/K6k w - - 0 1", &Position{Start: position("8/8/8/8/8/8/8/K6k w - - 0 1")}},
+	{"position fen 8/8/8/8/8/8/8/K6k w - - 0 1 moves a1a2", &Position{Start: position("8/8/8/8/8/8/8/K6k w - - 0 1"), Moves: lan("a1a2")}},
+	{"register later", &Register{Later: true}},
+	{"register name Stefan MK code 4359874324", &Register{Name: "Stefan MK", Code: "4359874324"}},
+	{"register code 1234", &Register{Code: "1234"}},
+	{"registration ok", &Registration{Status: StatusOK}},
+	{"setoption name Clear Hash", &SetOption{Name: "Clear Hash"}},
+	{"setoption name Hash value 128", &SetOption{Name: "Hash", Value: "128"}},
+	{"setoption name Book File value my book.bin", &SetOption{Name: "Book File", Value: "my book.bin"}},
+	{"setoption name UCI_Opponent value none 2800 computer Some Engine", &SetOption{Name: "UCI_Opponent", Value: "none 2800 computer Some Engine"}},
+	{"go nodes 100000 mate 3", &Go{Nodes: 100000, Mate: 3}},
+	{"readyok", &ReadyOk{}},
+	{"uciok", &UCIOk{}},
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, tt := range roundTripTests {
+		text, err := tt.msg.MarshalText()
+		if err != nil {
+			t.Errorf("%q: marshal: %v", tt.text, err)
+		} else if string(text) != tt.text {
+			t.Errorf("marshal: got %q, want %q", text, tt.text)
+		}
+
+		got := reflect.New(reflect.TypeOf(tt.msg).Elem()).Interface().(Message)
+		if err := got.UnmarshalText([]byte(tt.text)); err != nil {
+			t.Errorf("%q: unmarshal: %v", tt.text, err)
+			continue
+		}
+		if diff := cmp.Diff(tt.msg, got, cmpOpts...); diff != "" {
+			t.Errorf("%q: unmarshal (-want, +got)\n%s", tt.text, diff)
+		}
+	}
+}
+
+func TestUnmarshalText_Invalid(t *testing.T) {
+	cases := []struct {
+		text string
+		msg  Message
+	}{
+		{"bestmove", &BestMove{}},
+		{"bestmove e9e4", &BestMove{}},
+		{"bestmove e2e4 ponder", &BestMove{}},
+		{"bestmove e2e4 ponder e7e5x", &BestMove{}},
+		{"copyprotection", &CopyProtection{}},
+		{"copyprotection maybe", &CopyProtection{}},
+		{"debug", &Debug{}},
+		{"debug yes", &Debug{}},
+		{"go depth", &Go{}},
+		{"go depth x", &Go{}},
+		{"go depth -1", &Go{}},
+		{"go nodes -1", &Go{}},
+		{"go movetime -1", &Go{}},
+		{"go fast", &Go{}},
+		{"go searchmoves e2e4 castle", &Go{}},
+		{"id name", &ID{}},
+		{"id version 1", &ID{}},
+		{"option name Hash", &Option{}},
+		{"option name type spin", &Option{}},
+		{"option name Hash type slider", &Option{}},
+		{"option name Hash type spin default 16", &Option{}},
+		{"option name Hash type spin default 16 min x max 2", &Option{}},
+		{"position", &Position{}},
+		{"position fen", &Position{}},
+		{"position fen moves e2e4", &Position{}},
+		{"position somewhere", &Position{}},
+		{"position startpos moves e2e4 e7", &Position{}},
+		{"position fen 8/8/8 w - - 0 1", &Position{}},
+		{"position fen rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR x KQkq - 0 1", &Position{}},
+		{"position fen 8/8/8/8/8/8/8/8 w - - 0 1", &Position{}},
+		{"position fen 4k3/8/8/8/8/8/8/4R1K1 w - - 0 1", &Position{}},
+		{"isready now", &IsReady{}},
+		{"register", &Register{}},
+		{"register name", &Register{}},
+		{"register email a@b", &Register{}},
+		{"registration", &Registration{}},
+		{"setoption", &SetOption{}},
+		{"setoption name", &SetOption{}},
+		{"setoption value 1", &SetOption{}},
+	}
+
+	for _, c := range cases {
+		if err := c.msg.UnmarshalText([]byte(c.text)); err == nil {
+			t.Errorf("%q: expected error, got %+v", c.text, c.msg)
+		}
+	}
+}
+
+func TestMarshalText_Invalid(t *testing.T) {
+	cases := []Message{
+		&CopyProtection{},
+		&ID{},
+		&ID{Name: "Aloe", Author: "the Aloe authors"},
+		&Option{Type: OptionTypeCheck},
+		&Option{Name: "Hash", Type: "slider"},
+		&Position{},
+		&Position{Moves: lan("e2e4")},
+		&Register{},
+		&Register{Later: true, Name: "Stefan MK"},
+		&Registration{Status: "pending"},
+		&SetOption{},
+	}
+
+	for _, c := range cases {
+		if text, err := c.MarshalText(); err == nil {
+			t.Errorf("%+v: expected error, got %q", c, text)
+		}
+	}
+}
+
+func TestPosition_StartingFEN(t *testing.T) {
+	var p Position
+	if err := p.UnmarshalText([]byte("position fen " + fen.StartingFEN + " moves e2e4")); err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := p.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "position startpos moves e2e4"; string(text) != want {
+		t.Errorf("want %q, got %q", want, text)
+	}
+}
+
+func TestGo_UnmarshalText_ExpiredClock(t *testing.T) {
+	cases := []struct {
+		text string
+		want *Go
+	}{
+		{"go wtime -250 btime 1000", &Go{WhiteTime: 1, BlackTime: 1000}},
+		{"go wtime 1000 btime 0 binc 100", &Go{WhiteTime: 1000, BlackTime: 1, BlackIncrement: 100}},
+		{"go wtime 1000 btime 1000 winc -10 movestogo -1", &Go{WhiteTime: 1000, BlackTime: 1000}},
+	}
+
+	for _, c := range cases {
+		var got Go
+		if err := got.UnmarshalText([]byte(c.text)); err != nil {
+			t.Errorf("%q: %v", c.text, err)
+			continue
+		}
+		if diff := cmp.Diff(c.want, &got, cmpOpts...); diff != "" {
+			t.Errorf("%q: (-want, +got)\n%s", c.text, diff)
+		}
+	}
+}
+
+func TestEmptyMoveLists(t *testing.T) {
+	cases := []struct {
+		text string
+		want Message
+	}{
+		{"position startpos moves", &Position{Start: chess.NewPosition()}},
+		{"go searchmoves", &Go{}},
+		{"go searchmoves depth 3", &Go{Depth: 3}},
+	}
+
+	for _, c := range cases {
+		got, err := Parse(c.text)
+		if err != nil {
+			t.Errorf("%q: %v", c.text, err)
+			continue
+		}
+		if diff := cmp.Diff(c.want, got, cmpOpts...); diff != "" {
+			t.Errorf("%q: (-want, +got)\n%s", c.text, diff)
+		}
+	}
+}
+
+func TestNullMove(t *testing.T) {
+	// The null move can be sent, but is never legal in a position.
+	var p Position
+	if err := p.UnmarshalText([]byte("position startpos moves e2e4 0000")); err != nil {
+		t.Fatal(err)
+	}
+	if want := []chess.Move{move("e2e4"), {}}; !cmp.Equal(want, p.Moves) {
+		t.Errorf("want %v, got %v", want, p.Moves)
+	}
+
+	// Some engines say they have no move with "(none)" rather than "0000".
+	var b BestMove
+	if err := b.UnmarshalText([]byte("bestmove (none)")); err != nil {
+		t.Fatal(err)
+	}
+	if b != (BestMove{}) {
+		t.Errorf("want the null move, got %+v", b)
+	}
+	if text, _ := b.MarshalText(); string(text) != "bestmove 0000" {
+		t.Errorf("want bestmove 0000, got %q", text)
+	}
+}
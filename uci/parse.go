@@ -0,0 +1,111 @@
+package uci
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Parse parses a line of input into the message it contains.
+//
+// As the UCI specification requires, unknown tokens before the command are
+// skipped, so "joho debug on" parses as "debug on". If the line contains no
+// known command, Parse returns an [*UnknownCommandError].
+func Parse(line string) (Message, error) {
+	m, _, err := parse(line)
+	return m, err
+}
+
+// ParseRequest is like [Parse], but returns an error if the line doesn't
+// contain a request.
+func ParseRequest(line string) (Request, error) {
+	m, cmd, err := parse(line)
+	if err != nil {
+		return nil, err
+	}
+	req, ok := m.(Request)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response %q", cmd)
+	}
+	return req, nil
+}
+
+// ParseResponse is like [Parse], but returns an error if the line doesn't
+// contain a response.
+func ParseResponse(line string) (Response, error) {
+	m, cmd, err := parse(line)
+	if err != nil {
+		return nil, err
+	}
+	resp, ok := m.(Response)
+	if !ok {
+		return nil, fmt.Errorf("unexpected request %q", cmd)
+	}
+	return resp, nil
+}
+
+// parse returns the message in line and its command.
+func parse(line string) (Message, string, error) {
+	line = strings.TrimRight(line, " \t\r\n")
+
+	if strings.TrimSpace(line) == "" {
+		return nil, "", fmt.Errorf("blank message")
+	}
+
+	first := ""
+	for rest := line; ; {
+		rest = strings.TrimLeft(rest, " \t")
+		if rest == "" {
+			return nil, "", &UnknownCommandError{Command: first}
+		}
+
+		cmd := rest
+		if i := strings.IndexAny(rest, " \t"); i >= 0 {
+			cmd = rest[:i]
+		}
+		if first == "" {
+			first = cmd
+		}
+
+		if newMessage, ok := messages[cmd]; ok {
+			m := newMessage()
+			if err := m.UnmarshalText([]byte(rest)); err != nil {
+				return nil, "", err
+			}
+			return m, cmd, nil
+		}
+
+		rest = rest[len(cmd):]
+	}
+}
+
+// messages maps each command to a constructor of its message type.
+var messages = map[string]func() Message{
+	"bestmove":       func() Message { return new(BestMove) },
+	"copyprotection": func() Message { return new(CopyProtection) },
+	"debug":          func() Message { return new(Debug) },
+	"go":             func() Message { return new(Go) },
+	"id":             func() Message { return new(ID) },
+	"info":           func() Message { return new(Info) },
+	"isready":        func() Message { return new(IsReady) },
+	"option":         func() Message { return new(Option) },
+	"ponderhit":      func() Message { return new(PonderHit) },
+	"position":       func() Message { return new(Position) },
+	"quit":           func() Message { return new(Quit) },
+	"readyok":        func() Message { return new(ReadyOk) },
+	"register":       func() Message { return new(Register) },
+	"registration":   func() Message { return new(Registration) },
+	"setoption":      func() Message { return new(SetOption) },
+	"stop":           func() Message { return new(Stop) },
+	"uci":            func() Message { return new(UCI) },
+	"ucinewgame":     func() Message { return new(UCINewGame) },
+	"uciok":          func() Message { return new(UCIOk) },
+}
+
+// An UnknownCommandError is returned when a line contains no known command.
+type UnknownCommandError struct {
+	Command string // The first token of the line.
+}
+
+func (e *UnknownCommandError) Error() string {
+	return fmt.Sprintf("unknown command %q", e.Command)
+}
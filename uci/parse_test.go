@@ -0,0 +1,91 @@
+package uci
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/clfs/aloe/fen"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		in   string
+		want Message
+	}{
+		{"uci", &UCI{}},
+		{"debug on", &Debug{Flag: true}},
+		{"joho debug on", &Debug{Flag: true}},
+		{"isready", &IsReady{}},
+		{"  isready  ", &IsReady{}},
+		{"isready\r\n", &IsReady{}},
+		{"setoption name Hash value 32", &SetOption{Name: "Hash", Value: "32"}},
+		{"ucinewgame", &UCINewGame{}},
+		{"position startpos moves e2e4", &Position{position(fen.StartingFEN), lan("e2e4")}},
+		{"go  depth 5", &Go{Depth: 5}},
+		{"stop", &Stop{}},
+		{"ponderhit", &PonderHit{}},
+		{"quit", &Quit{}},
+		{"id name Aloe", &ID{Name: "Aloe"}},
+		{"readyok", &ReadyOk{}},
+		{"bestmove e2e4", &BestMove{Move: move("e2e4")}},
+	}
+
+	for _, c := range cases {
+		got, err := Parse(c.in)
+		if err != nil {
+			t.Errorf("%q: error: %v", c.in, err)
+			continue
+		}
+		if reflect.TypeOf(got) != reflect.TypeOf(c.want) {
+			t.Errorf("%q: want %T, got %T", c.in, c.want, got)
+			continue
+		}
+		if diff := cmp.Diff(c.want, got, cmpOpts...); diff != "" {
+			t.Errorf("%q: (-want, +got)\n%s", c.in, diff)
+		}
+	}
+
+	for _, in := range []string{"", "   ", "hello", "go depth", "debug maybe"} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("%q: expected error, got nil", in)
+		}
+	}
+}
+
+func TestParse_UnknownCommand(t *testing.T) {
+	_, err := Parse("joho hello world")
+
+	var unknown *UnknownCommandError
+	if !errors.As(err, &unknown) {
+		t.Fatalf("want *UnknownCommandError, got %v", err)
+	}
+	if unknown.Command != "joho" {
+		t.Errorf("want command joho, got %q", unknown.Command)
+	}
+}
+
+func TestParseRequest(t *testing.T) {
+	if req, err := ParseRequest("go infinite"); err != nil {
+		t.Error(err)
+	} else if _, ok := req.(*Go); !ok {
+		t.Errorf("want *Go, got %T", req)
+	}
+
+	if _, err := ParseRequest("bestmove e2e4"); err == nil {
+		t.Error("want error for a response")
+	}
+}
+
+func TestParseResponse(t *testing.T) {
+	if resp, err := ParseResponse("bestmove e2e4"); err != nil {
+		t.Error(err)
+	} else if _, ok := resp.(*BestMove); !ok {
+		t.Errorf("want *BestMove, got %T", resp)
+	}
+
+	if _, err := ParseResponse("go infinite"); err == nil {
+		t.Error("want error for a request")
+	}
+}
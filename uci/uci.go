@@ -1,7 +1,33 @@
 // Package uci describes the Universal Chess Interface (UCI) protocol.
+//
+// Each UCI command is a [Message] type. Commands sent from the GUI to the
+// engine also implement [Request], and commands sent from the engine to the
+// GUI implement [Response], so a message can't be sent the wrong way. Package
+// [github.com/clfs/aloe/encoding/uci] streams messages over a connection.
 package uci
 
-import "errors"
+import (
+	"encoding"
+	"errors"
+)
+
+// Message is the interface implemented by all UCI messages.
+type Message interface {
+	encoding.TextMarshaler
+	encoding.TextUnmarshaler
+}
+
+// A Request is a message sent from the GUI to the engine.
+type Request interface {
+	Message
+	request()
+}
+
+// A Response is a message sent from the engine to the GUI.
+type Response interface {
+	Message
+	response()
+}
 
 type Engine interface {
 	// Do blocks until the request is completed or an error occurs.